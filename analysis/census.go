@@ -0,0 +1,358 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Unknown is the census name given to pieces of touching cells that aren't part of any known object.
+const Unknown = "other"
+
+// object is a known Game of Life object, given as its first phase in rows of 'O' (alive) and '.' (dead).
+type object struct {
+	name   string
+	period int
+	rows   []string
+}
+
+// objects are the patterns recognised by Census. Every phase of the oscillators and spaceships
+// is generated from the one given here, so only one phase needs to be written down.
+var objects = []object{
+	{"block", 1, []string{"OO", "OO"}},
+	{"beehive", 1, []string{".OO.", "O..O", ".OO."}},
+	{"loaf", 1, []string{".OO.", "O..O", ".O.O", "..O."}},
+	{"boat", 1, []string{"OO.", "O.O", ".O."}},
+	{"ship", 1, []string{"OO.", "O.O", ".OO"}},
+	{"tub", 1, []string{".O.", "O.O", ".O."}},
+	{"pond", 1, []string{".OO.", "O..O", "O..O", ".OO."}},
+	{"barge", 1, []string{".O..", "O.O.", ".O.O", "..O."}},
+	{"blinker", 2, []string{"OOO"}},
+	{"toad", 2, []string{".OOO", "OOO."}},
+	{"beacon", 2, []string{"OO..", "OO..", "..OO", "..OO"}},
+	{"pulsar", 3, []string{
+		"..OOO...OOO..",
+		".............",
+		"O....O.O....O",
+		"O....O.O....O",
+		"O....O.O....O",
+		"..OOO...OOO..",
+		".............",
+		"..OOO...OOO..",
+		"O....O.O....O",
+		"O....O.O....O",
+		"O....O.O....O",
+		".............",
+		"..OOO...OOO..",
+	}},
+	{"pentadecathlon", 15, []string{"..O....O..", "OO.OOOO.OO", "..O....O.."}},
+	{"glider", 4, []string{".O.", "..O", "OOO"}},
+	{"LWSS", 4, []string{".O..O", "O....", "O...O", "OOOO."}},
+	{"MWSS", 4, []string{"...O..", ".O...O", "O.....", "O....O", "OOOOO."}},
+	{"HWSS", 4, []string{"...OO..", ".O....O", "O......", "O.....O", "OOOOOO."}},
+}
+
+// library maps the canonical key of every phase of every object to the object's name.
+var library = make(map[string]string)
+
+// placements maps the normalisedKey of a piece of touching cells to every object, phase and orientation
+// with a piece like it, largest object first. Objects like the pulsar are made of several pieces,
+// any of which can be used to find where the rest of it should be.
+var placements = make(map[string][]placement)
+
+// placement is one phase of an object in one orientation, with its cells relative to the
+// top left corner of the bounding box of one of its pieces.
+type placement struct {
+	name  string
+	cells []util.Cell
+}
+
+func init() {
+	for _, o := range objects {
+		var cells []util.Cell
+		for y, row := range o.rows {
+			for x, c := range row {
+				if c == 'O' {
+					cells = append(cells, util.Cell{X: x, Y: y})
+				}
+			}
+		}
+		first := canonicalKey(cells)
+		for phase := 0; phase < o.period; phase++ {
+			library[canonicalKey(cells)] = o.name
+			addPlacements(o.name, cells)
+			cells = step(cells)
+		}
+		if canonicalKey(cells) != first {
+			panic("census object " + o.name + " does not repeat after its period")
+		}
+	}
+	for key := range placements {
+		sort.SliceStable(placements[key], func(i, j int) bool {
+			return len(placements[key][i].cells) > len(placements[key][j].cells)
+		})
+	}
+}
+
+// addPlacements adds a phase of an object in all 8 rotations and reflections, once for each of its pieces.
+func addPlacements(name string, cells []util.Cell) {
+	added := make(map[string]bool)
+	for t := 0; t < 8; t++ {
+		oriented := transform(cells, t)
+		for _, piece := range touching(oriented) {
+			minX, minY := corner(piece)
+			moved := make([]util.Cell, len(oriented))
+			for i, c := range oriented {
+				moved[i] = util.Cell{X: c.X - minX, Y: c.Y - minY}
+			}
+			//symmetric objects give the same placement more than once
+			pieceKey := normalisedKey(piece)
+			if key := pieceKey + "|" + cellsKey(moved); !added[key] {
+				added[key] = true
+				placements[pieceKey] = append(placements[pieceKey], placement{name, moved})
+			}
+		}
+	}
+}
+
+// Census counts how many of each known object there are in the world. The world is split into pieces
+// of touching cells, and each piece is looked up to find the objects it could be part of. The largest
+// object whose cells are all alive with nothing else touching them is counted, so objects close together
+// like a bi-block are still told apart. Pieces that aren't part of any object are counted under Unknown.
+// The world wraps around at the edges like the board, and only B3/S23 objects are recognised.
+func Census(world [][]byte) map[string]int {
+	census := make(map[string]int)
+	counted := make(map[util.Cell]bool) //cells, wrapped onto the board, of objects already counted
+	for _, piece := range Islands(world) {
+		if counted[wrap(world, piece[0])] {
+			continue
+		}
+		census[match(world, piece, counted)]++
+	}
+	return census
+}
+
+// match names the object the piece is part of, marking all of its cells as counted.
+func match(world [][]byte, piece []util.Cell, counted map[util.Cell]bool) string {
+	minX, minY := corner(piece)
+	for _, pl := range placements[normalisedKey(piece)] {
+		cells := make(map[util.Cell]bool, len(pl.cells))
+		for _, c := range pl.cells {
+			cells[wrap(world, util.Cell{X: minX + c.X, Y: minY + c.Y})] = true
+		}
+		if len(cells) == len(pl.cells) && isolated(world, cells, counted) {
+			for c := range cells {
+				counted[c] = true
+			}
+			return pl.name
+		}
+	}
+	return Unknown
+}
+
+// isolated reports whether every one of the cells is alive and not yet counted, and no other cell next to them is alive.
+func isolated(world [][]byte, cells, counted map[util.Cell]bool) bool {
+	for c := range cells {
+		if world[c.Y][c.X] == 0 || counted[c] {
+			return false
+		}
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				next := wrap(world, util.Cell{X: c.X + dx, Y: c.Y + dy})
+				if world[next.Y][next.X] != 0 && !cells[next] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// wrap moves a cell onto the board, which wraps around at the edges.
+func wrap(world [][]byte, c util.Cell) util.Cell {
+	height, width := len(world), len(world[0])
+	return util.Cell{X: ((c.X % width) + width) % width, Y: ((c.Y % height) + height) % height}
+}
+
+// Classify names the object made up of the given cells, in any position, orientation or phase.
+func Classify(cells []util.Cell) string {
+	if name, ok := library[canonicalKey(cells)]; ok {
+		return name
+	}
+	return Unknown
+}
+
+// Islands groups the live cells of the world into islands of touching cells (diagonals included).
+// Cells of an island that wraps around an edge are returned with coordinates continuing past that edge,
+// so the island stays in one piece.
+func Islands(world [][]byte) [][]util.Cell {
+	height := len(world)
+	if height == 0 {
+		return nil
+	}
+	width := len(world[0])
+
+	visited := make([][]bool, height)
+	for y := range visited {
+		visited[y] = make([]bool, width)
+	}
+
+	var islands [][]util.Cell
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if world[y][x] == 0 || visited[y][x] {
+				continue
+			}
+			//flood fill from this cell, remembering unwrapped positions
+			visited[y][x] = true
+			island := []util.Cell{{X: x, Y: y}}
+			for i := 0; i < len(island); i++ {
+				cell := island[i]
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := cell.X+dx, cell.Y+dy
+						wx, wy := ((nx%width)+width)%width, ((ny%height)+height)%height
+						if world[wy][wx] != 0 && !visited[wy][wx] {
+							visited[wy][wx] = true
+							island = append(island, util.Cell{X: nx, Y: ny})
+						}
+					}
+				}
+			}
+			islands = append(islands, island)
+		}
+	}
+	return islands
+}
+
+// touching splits cells into pieces where every cell is next to (diagonals included) another in the same piece.
+func touching(cells []util.Cell) [][]util.Cell {
+	left := make(map[util.Cell]bool, len(cells))
+	for _, c := range cells {
+		left[c] = true
+	}
+	var pieces [][]util.Cell
+	for _, c := range cells {
+		if !left[c] {
+			continue
+		}
+		delete(left, c)
+		piece := []util.Cell{c}
+		for i := 0; i < len(piece); i++ {
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					next := util.Cell{X: piece[i].X + dx, Y: piece[i].Y + dy}
+					if left[next] {
+						delete(left, next)
+						piece = append(piece, next)
+					}
+				}
+			}
+		}
+		pieces = append(pieces, piece)
+	}
+	return pieces
+}
+
+// canonicalKey describes a set of cells in a way that doesn't depend on where they are or which
+// way round they are: it is the smallest description over all 8 rotations and reflections.
+func canonicalKey(cells []util.Cell) string {
+	key := ""
+	for t := 0; t < 8; t++ {
+		k := normalisedKey(transform(cells, t))
+		if t == 0 || k < key {
+			key = k
+		}
+	}
+	return key
+}
+
+// transform returns the cells in one of the 8 rotations and reflections, numbered 0 (unchanged) to 7.
+func transform(cells []util.Cell, t int) []util.Cell {
+	transformed := make([]util.Cell, len(cells))
+	for i, c := range cells {
+		x, y := c.X, c.Y
+		if t&1 != 0 {
+			x = -x
+		}
+		if t&2 != 0 {
+			y = -y
+		}
+		if t&4 != 0 {
+			x, y = y, x
+		}
+		transformed[i] = util.Cell{X: x, Y: y}
+	}
+	return transformed
+}
+
+// corner returns the top left corner of the bounding box of the cells.
+func corner(cells []util.Cell) (int, int) {
+	minX, minY := cells[0].X, cells[0].Y
+	for _, c := range cells {
+		if c.X < minX {
+			minX = c.X
+		}
+		if c.Y < minY {
+			minY = c.Y
+		}
+	}
+	return minX, minY
+}
+
+// normalisedKey moves cells so their bounding box starts at (0, 0) and lists them in order.
+func normalisedKey(cells []util.Cell) string {
+	if len(cells) == 0 {
+		return ""
+	}
+	minX, minY := corner(cells)
+	moved := make([]util.Cell, len(cells))
+	for i, c := range cells {
+		moved[i] = util.Cell{X: c.X - minX, Y: c.Y - minY}
+	}
+	return cellsKey(moved)
+}
+
+// cellsKey lists the cells in order, where they are.
+func cellsKey(cells []util.Cell) string {
+	sorted := append([]util.Cell{}, cells...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y < sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+	var b strings.Builder
+	for _, c := range sorted {
+		b.WriteString(strconv.Itoa(c.X))
+		b.WriteByte(',')
+		b.WriteString(strconv.Itoa(c.Y))
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+// step evolves a small pattern by one B3/S23 turn on an unbounded plane.
+func step(cells []util.Cell) []util.Cell {
+	alive := make(map[util.Cell]bool)
+	counts := make(map[util.Cell]int)
+	for _, c := range cells {
+		alive[c] = true
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dx != 0 || dy != 0 {
+					counts[util.Cell{X: c.X + dx, Y: c.Y + dy}]++
+				}
+			}
+		}
+	}
+	var next []util.Cell
+	for c, n := range counts {
+		if n == 3 || (n == 2 && alive[c]) {
+			next = append(next, c)
+		}
+	}
+	return next
+}
@@ -0,0 +1,87 @@
+package analysis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestCensusPhases checks that every phase of every object is recognised on its own in all 8 orientations,
+// including where it wraps around the edges of the board.
+func TestCensusPhases(t *testing.T) {
+	for _, o := range objects {
+		var cells []util.Cell
+		for y, row := range o.rows {
+			for x, c := range row {
+				if c == 'O' {
+					cells = append(cells, util.Cell{X: x, Y: y})
+				}
+			}
+		}
+		for phase := 0; phase < o.period; phase++ {
+			for orientation := 0; orientation < 8; orientation++ {
+				for _, at := range []util.Cell{{X: 10, Y: 10}, {X: 28, Y: 30}} {
+					world := make([][]byte, 32)
+					for y := range world {
+						world[y] = make([]byte, 32)
+					}
+					transformed := transform(cells, orientation)
+					minX, minY := corner(transformed)
+					for _, c := range transformed {
+						world[(at.Y+c.Y-minY)%32][(at.X+c.X-minX)%32] = 0xFF
+					}
+					expected := map[string]int{o.name: 1}
+					if census := Census(world); !reflect.DeepEqual(census, expected) {
+						t.Errorf("%v phase %v, orientation %v at %v: expected %v, got %v", o.name, phase, orientation, at, expected, census)
+					}
+				}
+			}
+			cells = step(cells)
+		}
+	}
+}
+
+// TestCensusNeighbours checks that each phase of the pentadecathlon and pulsar is still recognised
+// with a blinker two cells from its side, rather than any of their pieces being taken for blinkers.
+func TestCensusNeighbours(t *testing.T) {
+	for _, o := range objects {
+		if o.name != "pentadecathlon" && o.name != "pulsar" {
+			continue
+		}
+		var cells []util.Cell
+		for y, row := range o.rows {
+			for x, c := range row {
+				if c == 'O' {
+					cells = append(cells, util.Cell{X: x, Y: y})
+				}
+			}
+		}
+		for phase := 0; phase < o.period; phase++ {
+			t.Run(fmt.Sprintf("%v-%v", o.name, phase), func(t *testing.T) {
+				world := make([][]byte, 32)
+				for y := range world {
+					world[y] = make([]byte, 32)
+				}
+				minX, minY := corner(cells)
+				maxX := minX
+				for _, c := range cells {
+					world[8+c.Y-minY][8+c.X-minX] = 0xFF
+					if c.X > maxX {
+						maxX = c.X
+					}
+				}
+				//a vertical blinker, with one dead column between it and the object
+				for y := 8; y < 11; y++ {
+					world[y][8+maxX-minX+2] = 0xFF
+				}
+				expected := map[string]int{o.name: 1, "blinker": 1}
+				if census := Census(world); !reflect.DeepEqual(census, expected) {
+					t.Errorf("expected %v, got %v", expected, census)
+				}
+			})
+			cells = step(cells)
+		}
+	}
+}
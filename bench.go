@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// bench runs the same game headless with each number of threads and prints how fast it went,
+// like BenchmarkGol but without needing go test.
+func bench(args []string) {
+	flags := newFlags("bench")
+	var p gol.Params
+	flags.IntVar(&p.ImageWidth, "w", 512, "Specify the width of the image. Defaults to 512.")
+	flags.IntVar(&p.ImageHeight, "h", 512, "Specify the height of the image. Defaults to 512.")
+	flags.IntVar(&p.Turns, "turns", 100, "Specify the number of turns to process. Defaults to 100.")
+	flags.StringVar(&p.Rule, "rule", gol.DefaultRule, "Specify the rule in B/S notation. Defaults to B3/S23.")
+	threadList := flags.String("threads", "1,2,4,8,16", "Specify the numbers of worker threads to time, separated by commas.")
+	runs := flags.Int("runs", 3, "Specify how many times to run each, keeping the fastest.")
+	_ = flags.Parse(args)
+
+	var threads []int
+	for _, field := range strings.Split(*threadList, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n <= 0 {
+			fmt.Printf("Invalid thread count %q\n", field)
+			os.Exit(2)
+		}
+		threads = append(threads, n)
+	}
+	if p.Turns < 2 {
+		fmt.Println("At least 2 turns are needed to time them")
+		os.Exit(2)
+	}
+	//nothing but the results should be printed
+	p.ReportInterval = -1
+	p.Quiet = true
+
+	fmt.Printf("%-20v %12v %12v\n", "Benchmark", "End to end", "Turns/s")
+	for _, n := range threads {
+		p.Threads = n
+		var fastest, fastestTurns time.Duration
+		for i := 0; i < *runs; i++ {
+			total, turns := benchRun(p)
+			if fastest == 0 || total < fastest {
+				fastest = total
+			}
+			if fastestTurns == 0 || turns < fastestTurns {
+				fastestTurns = turns
+			}
+		}
+		name := fmt.Sprintf("%dx%dx%d-%d", p.ImageWidth, p.ImageHeight, p.Turns, p.Threads)
+		fmt.Printf("%-20v %12v %12.1f\n", name, fastest.Round(time.Microsecond), float64(p.Turns-1)/fastestTurns.Seconds())
+	}
+}
+
+// benchRun runs one game, returning how long it took end to end, including loading and saving the image,
+// and how long the turns after the first took. Timing from the end of the first turn leaves out loading
+// the image and sending a CellFlipped for every cell in it, and the final save comes after the last turn.
+func benchRun(p gol.Params) (total, turns time.Duration) {
+	start := time.Now()
+	events := make(chan gol.Event, 1000)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- gol.Run(p, events, nil)
+	}()
+	var first, last time.Time
+	for event := range events {
+		if e, ok := event.(gol.TurnComplete); ok {
+			switch e.CompletedTurns {
+			case 1:
+				first = time.Now()
+			case p.Turns:
+				last = time.Now()
+			}
+		}
+	}
+	total = time.Since(start)
+	if err := <-runErr; err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	return total, last.Sub(first)
+}
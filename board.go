@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// The formats boards can be converted to and from on top of gol.Formats.
+// Like those, each one is also the file extension.
+const (
+	formatPBM = "pbm" // a plain (P1) or binary (P4) bitmap, 1 for alive. Written as P4
+	formatRLE = "rle" // the run length encoding used by most Life programs, keeping the rule
+	formatPNG = "png" // a greyscale picture, white for alive like the pgm images
+)
+
+// boardFormats lists every format a board file can be in.
+func boardFormats() []string {
+	return append(append([]string{}, gol.Formats...), formatPBM, formatRLE, formatPNG)
+}
+
+// formatOf chooses the format of a file from its extension, empty if it isn't one of boardFormats.
+func formatOf(path string) string {
+	format := ""
+	for _, f := range boardFormats() {
+		//the longest match wins, so .pgm.gz isn't taken for .gz
+		if strings.HasSuffix(path, "."+f) && len(f) > len(format) {
+			format = f
+		}
+	}
+	return format
+}
+
+// readBoard reads a board in any of boardFormats, returning the cells row after row with 0 for dead and 0xFF for alive.
+// Files without a known extension are read as one of gol.Formats.
+func readBoard(path string) (gol.ImageInfo, []byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return gol.ImageInfo{}, nil, err
+	}
+	defer file.Close()
+	var info gol.ImageInfo
+	var cells []byte
+	switch formatOf(path) {
+	case formatPBM:
+		info, cells, err = decodePBM(bufio.NewReader(file))
+	case formatRLE:
+		info, cells, err = decodeRLE(file)
+	case formatPNG:
+		info, cells, err = decodePNG(file)
+	default:
+		info, cells, err = gol.DecodeImage(file)
+	}
+	if err != nil {
+		return info, nil, fmt.Errorf("%v: %v", path, err)
+	}
+	return info, cells, nil
+}
+
+// writeBoard writes a board in the format chosen by the extension of path.
+func writeBoard(path string, info gol.ImageInfo, cells []byte) error {
+	format := formatOf(path)
+	if format == "" {
+		return fmt.Errorf("%v: unknown format, expected one of %v", path, strings.Join(boardFormats(), ", "))
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	world := make([][]byte, info.Height)
+	for y := range world {
+		world[y] = cells[y*info.Width : (y+1)*info.Width]
+	}
+	switch format {
+	case formatPBM:
+		err = encodePBM(file, info, world)
+	case formatRLE:
+		err = encodeRLE(file, info, world)
+	case formatPNG:
+		err = encodePNG(file, info, world)
+	default:
+		err = gol.EncodeImage(file, format, info, world)
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// encodePBM writes a binary (P4) bitmap: each row padded to whole bytes, the first cell in the highest bit.
+func encodePBM(w io.Writer, info gol.ImageInfo, world [][]byte) error {
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "P4\n%v %v\n", info.Width, info.Height)
+	row := make([]byte, (info.Width+7)/8)
+	for y := range world {
+		for i := range row {
+			row[i] = 0
+		}
+		for x, cell := range world[y] {
+			if cell != 0 {
+				row[x/8] |= 0x80 >> uint(x%8)
+			}
+		}
+		_, _ = writer.Write(row)
+	}
+	return writer.Flush()
+}
+
+// decodePBM reads a plain (P1) or binary (P4) bitmap.
+func decodePBM(reader *bufio.Reader) (gol.ImageInfo, []byte, error) {
+	info := gol.ImageInfo{Topology: gol.TopologyTorus}
+	magic, err := gol.HeaderField(reader)
+	if err != nil || (magic != "P1" && magic != "P4") {
+		return info, nil, errors.New("not a pbm file")
+	}
+	var size [2]int
+	for i := range size {
+		field, err := gol.HeaderField(reader)
+		if err != nil {
+			return info, nil, fmt.Errorf("pbm header: %v", err)
+		}
+		size[i], err = strconv.Atoi(field)
+		if err != nil || size[i] <= 0 {
+			return info, nil, fmt.Errorf("pbm header: bad number %q", field)
+		}
+	}
+	info.Width, info.Height = size[0], size[1]
+	if err := gol.CheckSize(info, 0, 0); err != nil {
+		return info, nil, fmt.Errorf("pbm header: %v", err)
+	}
+	cells := make([]byte, info.Width*info.Height)
+
+	if magic == "P4" {
+		row := make([]byte, (info.Width+7)/8)
+		for y := 0; y < info.Height; y++ {
+			if _, err := io.ReadFull(reader, row); err != nil {
+				return info, nil, fmt.Errorf("expected %v rows of pbm data: %v", info.Height, err)
+			}
+			for x := 0; x < info.Width; x++ {
+				if row[x/8]&(0x80>>uint(x%8)) != 0 {
+					cells[y*info.Width+x] = 0xFF
+				}
+			}
+		}
+		return info, cells, nil
+	}
+	//plain pbm has a character per cell, whitespace between them is optional
+	for i := 0; i < len(cells); {
+		c, err := reader.ReadByte()
+		if err != nil {
+			return info, nil, fmt.Errorf("expected %v cells of pbm data: %v", len(cells), err)
+		}
+		switch c {
+		case '1':
+			cells[i] = 0xFF
+			i++
+		case '0':
+			i++
+		case '#':
+			_, _ = reader.ReadString('\n')
+		}
+	}
+	return info, cells, nil
+}
+
+// rleLineLength is the longest line written in rle files, as other programs expect.
+const rleLineLength = 70
+
+// encodeRLE writes a board in the run length encoding: a header line with the size and rule,
+// then runs of b (dead) and o (alive) with $ ending each row and ! at the end.
+func encodeRLE(w io.Writer, info gol.ImageInfo, world [][]byte) error {
+	writer := bufio.NewWriter(w)
+	rule := info.Rule
+	if rule == "" {
+		rule = gol.DefaultRule
+	}
+	fmt.Fprintf(writer, "x = %v, y = %v, rule = %v\n", info.Width, info.Height, rule)
+
+	line := 0
+	emit := func(count int, tag byte) {
+		run := string(tag)
+		if count > 1 {
+			run = strconv.Itoa(count) + run
+		}
+		if line+len(run) > rleLineLength {
+			_ = writer.WriteByte('\n')
+			line = 0
+		}
+		_, _ = writer.WriteString(run)
+		line += len(run)
+	}
+	//rows with nothing left in them are saved up, so a run of them becomes one n$
+	endOfRows := 0
+	for y := range world {
+		if y > 0 {
+			endOfRows++
+		}
+		for x := 0; x < len(world[y]); {
+			alive := world[y][x] != 0
+			count := 1
+			for x+count < len(world[y]) && (world[y][x+count] != 0) == alive {
+				count++
+			}
+			x += count
+			//dead cells at the end of a row are left out
+			if !alive && x == len(world[y]) {
+				break
+			}
+			if endOfRows > 0 {
+				emit(endOfRows, '$')
+				endOfRows = 0
+			}
+			if alive {
+				emit(count, 'o')
+			} else {
+				emit(count, 'b')
+			}
+		}
+	}
+	emit(1, '!')
+	_ = writer.WriteByte('\n')
+	return writer.Flush()
+}
+
+// decodeRLE reads a board in the run length encoding. Any tag other than b or . counts as alive,
+// so patterns with more than two states still load.
+func decodeRLE(r io.Reader) (gol.ImageInfo, []byte, error) {
+	info := gol.ImageInfo{Topology: gol.TopologyTorus}
+	scanner := bufio.NewScanner(r)
+	var cells []byte
+	x, y, count := 0, 0, 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case cells == nil:
+			if err := parseRLEHeader(line, &info); err != nil {
+				return info, nil, err
+			}
+			cells = make([]byte, info.Width*info.Height)
+			continue
+		}
+		for _, c := range line {
+			switch {
+			case c >= '0' && c <= '9':
+				//no run can be longer than the board, which also stops count overflowing
+				count = count*10 + int(c-'0')
+				if count > len(cells) {
+					return info, nil, fmt.Errorf("run of more than %v cells on a %vx%v board", len(cells), info.Width, info.Height)
+				}
+				continue
+			case c == ' ' || c == '\t':
+				continue
+			}
+			if count == 0 {
+				count = 1
+			}
+			switch c {
+			case '!':
+				return info, cells, nil
+			case '$':
+				x, y = 0, y+count
+				if y > info.Height {
+					return info, nil, fmt.Errorf("rows past the bottom of the %vx%v board", info.Width, info.Height)
+				}
+			case 'b', '.':
+				x += count
+				if x > info.Width {
+					return info, nil, fmt.Errorf("cells outside the %vx%v board at row %v", info.Width, info.Height, y)
+				}
+			default:
+				if y >= info.Height || x+count > info.Width {
+					return info, nil, fmt.Errorf("cells outside the %vx%v board at row %v", info.Width, info.Height, y)
+				}
+				for i := 0; i < count; i++ {
+					cells[y*info.Width+x+i] = 0xFF
+				}
+				x += count
+			}
+			count = 0
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return info, nil, err
+	}
+	if cells == nil {
+		return info, nil, errors.New("not an rle file")
+	}
+	return info, nil, errors.New("rle cells end without a !")
+}
+
+// parseRLEHeader reads a line like "x = 3, y = 3, rule = B3/S23".
+func parseRLEHeader(line string, info *gol.ImageInfo) error {
+	for _, part := range strings.Split(line, ",") {
+		pair := strings.SplitN(part, "=", 2)
+		if len(pair) != 2 {
+			return fmt.Errorf("rle header: bad part %q", part)
+		}
+		key, value := strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1])
+		var err error
+		switch key {
+		case "x":
+			info.Width, err = strconv.Atoi(value)
+		case "y":
+			info.Height, err = strconv.Atoi(value)
+		case "rule":
+			info.Rule = value
+		}
+		if err != nil {
+			return fmt.Errorf("rle header: bad number %q", value)
+		}
+	}
+	if err := gol.CheckSize(*info, 0, 0); err != nil {
+		return fmt.Errorf("rle header: %v", err)
+	}
+	return nil
+}
+
+// encodePNG writes a greyscale png, white for alive.
+func encodePNG(w io.Writer, info gol.ImageInfo, world [][]byte) error {
+	picture := image.NewGray(image.Rect(0, 0, info.Width, info.Height))
+	for y := range world {
+		copy(picture.Pix[y*picture.Stride:], world[y])
+	}
+	return png.Encode(w, picture)
+}
+
+// decodePNG reads any png, cells brighter than mid grey are alive.
+func decodePNG(r io.Reader) (gol.ImageInfo, []byte, error) {
+	//the header is read on its own first, so the size is checked before the picture is decoded
+	var header bytes.Buffer
+	config, err := png.DecodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return gol.ImageInfo{}, nil, err
+	}
+	info := gol.ImageInfo{Width: config.Width, Height: config.Height, Topology: gol.TopologyTorus}
+	if err := gol.CheckSize(info, 0, 0); err != nil {
+		return info, nil, fmt.Errorf("png header: %v", err)
+	}
+	picture, err := png.Decode(io.MultiReader(&header, r))
+	if err != nil {
+		return info, nil, err
+	}
+	bounds := picture.Bounds()
+	cells := make([]byte, info.Width*info.Height)
+	for y := 0; y < info.Height; y++ {
+		for x := 0; x < info.Width; x++ {
+			grey := color.GrayModel.Convert(picture.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			if grey.Y >= 128 {
+				cells[y*info.Width+x] = 0xFF
+			}
+		}
+	}
+	return info, cells, nil
+}
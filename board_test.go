@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestConvert checks that a board converted to every format the convert command knows comes back unchanged,
+// and that diffCells finds nothing between them.
+func TestConvert(t *testing.T) {
+	info, cells, err := readBoard("check/images/64x64x100.pgm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.Rule = "B36/S23"
+	_ = os.Mkdir("out", os.ModePerm)
+	for _, format := range boardFormats() {
+		t.Run(format, func(t *testing.T) {
+			path := "out/convert." + format
+			if err := writeBoard(path, info, cells); err != nil {
+				t.Fatal(err)
+			}
+			got, gotCells, err := readBoard(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Width != 64 || got.Height != 64 {
+				t.Fatalf("expected a 64x64 board, got %vx%v", got.Width, got.Height)
+			}
+			if differences := diffCells(cells, gotCells, 64); len(differences) > 0 {
+				t.Errorf("%v cells changed, the first at %v", len(differences), differences[0])
+			}
+			if (format == formatRLE || format == gol.FormatPacked) && got.Rule != info.Rule {
+				t.Errorf("expected rule %v to be kept, got %q", info.Rule, got.Rule)
+			}
+		})
+	}
+}
+
+// TestReadPatterns checks rle and plain pbm files written by hand, as other programs write them.
+func TestReadPatterns(t *testing.T) {
+	glider := "" +
+		"___\n" +
+		"__#\n" +
+		"###\n"
+	draw := func(width int, cells []byte) string {
+		var s strings.Builder
+		for i, cell := range cells {
+			if cell != 0 {
+				s.WriteByte('#')
+			} else {
+				s.WriteByte('_')
+			}
+			if i%width == width-1 {
+				s.WriteByte('\n')
+			}
+		}
+		return s.String()
+	}
+
+	_, cells, err := decodeRLE(strings.NewReader("#N Glider\n#C comment\nx = 3, y = 3, rule = B3/S23\n$2bo$\n3o!\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := draw(3, cells); got != glider {
+		t.Errorf("rle glider read as\n%v", got)
+	}
+
+	_, cells, err = decodePBM(bufio.NewReader(strings.NewReader("P1\n# glider\n3 3\n000\n0 0 1\n111\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := draw(3, cells); got != glider {
+		t.Errorf("pbm glider read as\n%v", got)
+	}
+
+	//boards that are impossible or too big are rejected before making room for them
+	for _, rle := range []string{
+		"x = 2, y = 2\n3o!\n",
+		"x = 3, y = 3\n9223372036854775808bo!\n",
+		"x = 3, y = 3\n4bo!\n",
+		"x = 3, y = 3\n4$o!\n",
+		"x = 2000000000, y = 2000000000\n!\n",
+	} {
+		if _, _, err := decodeRLE(strings.NewReader(rle)); err == nil {
+			t.Errorf("expected an error reading rle %q", rle)
+		}
+	}
+	for _, pbm := range []string{"P4 2000000000 2000000000\n", "P1 99999999999999999999 1\n"} {
+		if _, _, err := decodePBM(bufio.NewReader(strings.NewReader(pbm))); err == nil {
+			t.Errorf("expected an error reading pbm %q", pbm)
+		}
+	}
+	//a png header saying it's 20000x20000, with the checksum fixed up to match
+	var picture bytes.Buffer
+	if err := png.Encode(&picture, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	huge := picture.Bytes()
+	binary.BigEndian.PutUint32(huge[16:], 20000)
+	binary.BigEndian.PutUint32(huge[20:], 20000)
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+	if _, _, err := decodePNG(bytes.NewReader(huge)); err == nil || !strings.Contains(err.Error(), "png header") {
+		t.Errorf("expected a 20000x20000 png to be rejected from its header, got %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := encodeRLE(&out, gol.ImageInfo{Width: 3, Height: 3}, [][]byte{{0, 0, 0}, {0, 0, 0xFF}, {0xFF, 0xFF, 0xFF}}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "x = 3, y = 3, rule = B3/S23\n$2bo$3o!\n"; out.String() != expected {
+		t.Errorf("expected glider written as %q, got %q", expected, out.String())
+	}
+}
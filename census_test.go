@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/analysis"
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestCensus places known objects on a 32x32 board, in different orientations and phases and
+// wrapping around the edges, and checks that each of them is recognised, even when they are close together.
+func TestCensus(t *testing.T) {
+	world := make([][]byte, 32)
+	for i := range world {
+		world[i] = make([]byte, 32)
+	}
+	place := func(x, y int, rows ...string) {
+		for dy, row := range rows {
+			for dx, c := range row {
+				if c == 'O' {
+					world[(y+dy)%32][(x+dx)%32] = 0xFF
+				}
+			}
+		}
+	}
+	place(1, 1, "OO", "OO")
+	place(6, 1, "O", "O", "O")
+	place(10, 1, ".OO.", "O..O", ".OO.")
+	place(30, 14, "O.O", ".OO", ".O.")
+	place(1, 20, "O..O.", "....O", "O...O", ".OOOO")
+	place(20, 10, "OO..", "O...", "...O", "..OO")
+	//a bi-block and a tub two cells from a boat
+	place(20, 20, "OO.OO", "OO.OO")
+	place(12, 26, ".O..OO.", "O.O.O.O", ".O...O.")
+
+	census := analysis.Census(world)
+	expected := map[string]int{"block": 3, "blinker": 1, "beehive": 1, "glider": 1, "LWSS": 1, "beacon": 1, "tub": 1, "boat": 1}
+	if len(census) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, census)
+	}
+	for name, count := range expected {
+		if census[name] != count {
+			t.Errorf("expected %v %v, got %v", count, name, census[name])
+		}
+	}
+}
+
+// TestCensusEvent checks that a Census of the final board is sent before FinalTurnComplete when asked for.
+// The board starts with a block, a blinker and a glider, so after 8 turns the glider has moved on and
+// the blinker is back in its first phase.
+func TestCensusEvent(t *testing.T) {
+	cells := []util.Cell{
+		{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 1, Y: 2}, {X: 2, Y: 2},
+		{X: 10, Y: 1}, {X: 11, Y: 1}, {X: 12, Y: 1},
+		{X: 21, Y: 20}, {X: 22, Y: 21}, {X: 20, Y: 22}, {X: 21, Y: 22}, {X: 22, Y: 22},
+	}
+	p := gol.Params{Threads: 8, ImageWidth: 32, ImageHeight: 32, Turns: 8, Census: true, InitialCells: cells}
+	var census *gol.Census
+	final := false
+	_, err := runGame(p, func(event gol.Event) {
+		switch e := event.(type) {
+		case gol.Census:
+			if !final {
+				census = &e
+			}
+		case gol.FinalTurnComplete:
+			final = true
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if census == nil {
+		t.Fatal("no Census event received before FinalTurnComplete")
+	}
+	expected := map[string]int{"block": 1, "blinker": 1, "glider": 1}
+	if !reflect.DeepEqual(census.Objects, expected) {
+		t.Errorf("expected %v, got %v", expected, census.Objects)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestRunContext checks that cancelling the context stops the game after a few turns, reporting
+// the final state and Quitting before closing events, and saving the world only if asked to.
+func TestRunContext(t *testing.T) {
+	for _, save := range []bool{false, true} {
+		p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1, SaveOnCancel: save}
+		alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+		ctx, cancel := context.WithCancel(context.Background())
+		events := make(chan gol.Event)
+		result := make(chan error, 1)
+		go func() {
+			result <- gol.RunContext(ctx, p, events, nil)
+		}()
+
+		saves := 0
+		var final *gol.FinalTurnComplete
+		var last gol.Event
+		for event := range events {
+			switch e := event.(type) {
+			case gol.TurnComplete:
+				if e.CompletedTurns == 10 {
+					cancel()
+				}
+			case gol.ImageOutputComplete:
+				saves++
+			case gol.FinalTurnComplete:
+				final = &e
+			}
+			last = event
+		}
+		cancel()
+
+		if err := <-result; err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if final == nil {
+			t.Fatal("no FinalTurnComplete event received after cancelling")
+		}
+		if final.CompletedTurns < 10 || final.CompletedTurns >= p.Turns {
+			t.Errorf("expected the game to stop soon after turn 10, stopped at %v", final.CompletedTurns)
+		}
+		if expected, ok := alive[final.CompletedTurns]; ok && len(final.Alive) != expected {
+			t.Errorf("expected %v alive cells at turn %v, got %v", expected, final.CompletedTurns, len(final.Alive))
+		}
+		if state, ok := last.(gol.StateChange); !ok || state.NewState != gol.Quitting {
+			t.Errorf("expected the last event to be StateChange{Quitting}, got %v", last)
+		}
+		expectedSaves := 0
+		if save {
+			expectedSaves = 1
+		}
+		if saves != expectedSaves {
+			t.Errorf("expected %v images saved with SaveOnCancel %v, got %v", expectedSaves, save, saves)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// convert reads a board in one format and writes it in another, keeping the rule and turn where both formats can.
+func convert(args []string) {
+	flags := newFlags("convert")
+	_ = flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	info, cells, err := readBoard(flags.Arg(0))
+	if err == nil {
+		err = writeBoard(flags.Arg(1), info, cells)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Converted %vx%v board to %v\n", info.Width, info.Height, flags.Arg(1))
+}
+
+// info prints what is known about each board: its size and population, and the turn, rule and seed if they were saved.
+func info(args []string) {
+	flags := newFlags("info")
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	failed := false
+	for _, path := range flags.Args() {
+		info, cells, err := readBoard(path)
+		if err != nil {
+			fmt.Println("Error:", err)
+			failed = true
+			continue
+		}
+		alive := 0
+		for _, cell := range cells {
+			if cell != 0 {
+				alive++
+			}
+		}
+		format := formatOf(path)
+		if format == "" {
+			format = "unknown extension"
+		}
+		fmt.Println(path)
+		fmt.Println("  Format:", format)
+		fmt.Printf("  Size:   %vx%v\n", info.Width, info.Height)
+		fmt.Printf("  Alive:  %v (%.2f%%)\n", alive, 100*float64(alive)/float64(len(cells)))
+		if info.Rule != "" {
+			fmt.Println("  Rule:  ", info.Rule)
+		}
+		if info.Turn != 0 {
+			fmt.Println("  Turn:  ", info.Turn)
+		}
+		if info.Seed != 0 {
+			fmt.Println("  Seed:  ", info.Seed)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// diff compares two boards of the same size. Like diff(1) it exits with 1 if they differ.
+func diff(args []string) {
+	flags := newFlags("diff")
+	show := flags.Int("show", 10, "Specify how many of the differing cells to list.")
+	_ = flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	a, aCells, err := readBoard(flags.Arg(0))
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(2)
+	}
+	b, bCells, err := readBoard(flags.Arg(1))
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(2)
+	}
+	if a.Width != b.Width || a.Height != b.Height {
+		fmt.Printf("Boards are different sizes: %vx%v and %vx%v\n", a.Width, a.Height, b.Width, b.Height)
+		os.Exit(1)
+	}
+
+	differences := diffCells(aCells, bCells, a.Width)
+	if len(differences) == 0 {
+		fmt.Println("Boards are the same")
+		return
+	}
+	fmt.Printf("%v of %v cells differ\n", len(differences), len(aCells))
+	for i, cell := range differences {
+		if i == *show {
+			fmt.Printf("  ... and %v more\n", len(differences)-i)
+			break
+		}
+		only := flags.Arg(1)
+		if aCells[cell.Y*a.Width+cell.X] != 0 {
+			only = flags.Arg(0)
+		}
+		fmt.Printf("  (%v, %v) only alive in %v\n", cell.X, cell.Y, only)
+	}
+	os.Exit(1)
+}
+
+// diffCells returns every cell that is alive in one board but not the other, row by row.
+func diffCells(a, b []byte, width int) []util.Cell {
+	var differences []util.Cell
+	for i := range a {
+		if (a[i] != 0) != (b[i] != 0) {
+			differences = append(differences, util.Cell{X: i % width, Y: i / width})
+		}
+	}
+	return differences
+}
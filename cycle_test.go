@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestCycle checks that the 64x64 image is found to settle into its period 2 oscillation,
+// and that fast forwarding over a billion turns gives the same board as evolving normally.
+func TestCycle(t *testing.T) {
+	p := gol.Params{Threads: 8, ImageWidth: 64, ImageHeight: 64, Turns: 2000}
+	expected, cycle := runCycle(t, p)
+	if cycle == nil {
+		t.Fatal("no CycleDetected event received in 2000 turns")
+	}
+	if cycle.Period != 2 || cycle.CompletedTurns != cycle.FirstSeenTurn+cycle.Period {
+		t.Fatalf("expected a period 2 cycle, got %+v", *cycle)
+	}
+
+	for _, turns := range []int{2000, 1000000000, 1000000001} {
+		p := gol.Params{Threads: 8, ImageWidth: 64, ImageHeight: 64, Turns: turns, FastForward: true}
+		t.Run(fmt.Sprintf("%dx%dx%d-%d", p.ImageWidth, p.ImageHeight, p.Turns, p.Threads), func(t *testing.T) {
+			if turns%2 == 1 {
+				//odd turns end on the other phase of the oscillator
+				expected, _ = runCycle(t, gol.Params{Threads: 8, ImageWidth: 64, ImageHeight: 64, Turns: 2001})
+			}
+			given, fastCycle := runCycle(t, p)
+			if fastCycle == nil || *fastCycle != *cycle {
+				t.Errorf("expected %v, got %v", cycle, fastCycle)
+			}
+			assertEqualBoard(t, given, expected, p)
+		})
+	}
+}
+
+// TestCycleTurns checks that a repeat is reported on the first turn the world is the same as an earlier one,
+// with the turn it was first seen at.
+func TestCycleTurns(t *testing.T) {
+	tests := []struct {
+		name     string
+		cells    []util.Cell
+		expected gol.CycleDetected
+	}{
+		{"block", []util.Cell{{X: 3, Y: 3}, {X: 4, Y: 3}, {X: 3, Y: 4}, {X: 4, Y: 4}}, gol.CycleDetected{CompletedTurns: 1, Period: 1}},
+		{"blinker", []util.Cell{{X: 2, Y: 3}, {X: 3, Y: 3}, {X: 4, Y: 3}}, gol.CycleDetected{CompletedTurns: 2, Period: 2}},
+		//a glider moves a cell diagonally every 4 turns, so it takes 32 to get back to where it started on an 8x8 torus
+		{"glider", []util.Cell{{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}}, gol.CycleDetected{CompletedTurns: 32, Period: 32}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := gol.Params{Threads: 4, ImageWidth: 8, ImageHeight: 8, Turns: 100, InitialCells: test.cells}
+			_, cycle := runCycle(t, p)
+			if cycle == nil || *cycle != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, cycle)
+			}
+		})
+	}
+}
+
+// runCycle runs a game to the end, returning the final alive cells and the CycleDetected event if there was one.
+// The test fails if the game returns an error.
+func runCycle(t *testing.T, p gol.Params) ([]util.Cell, *gol.CycleDetected) {
+	var cycle *gol.CycleDetected
+	cells, err := runGame(p, func(event gol.Event) {
+		if e, ok := event.(gol.CycleDetected); ok {
+			cycle = &e
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cells, cycle
+}
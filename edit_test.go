@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestEdit checks that cells edited while paused are flipped, and end up in FinalTurnComplete
+// and in the image saved when quitting.
+func TestEdit(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1}
+	initial := readAliveCells("images/16x16.pgm", p.ImageWidth, p.ImageHeight)
+	killed := initial[0]
+	born := util.Cell{X: 0, Y: 0}
+	for _, cell := range initial {
+		if cell == born {
+			t.Fatal("expected the top left cell to start dead")
+		}
+	}
+
+	keyPresses := make(chan rune, 10)
+	edits := make(chan gol.CellEdit, 10)
+	keyPresses <- 'p'
+
+	var flipped []util.Cell
+	paused := false
+	final, err := runGameWithEdits(p, keyPresses, edits, func(event gol.Event) {
+		switch e := event.(type) {
+		case gol.StateChange:
+			if e.NewState == gol.Paused {
+				paused = true
+				edits <- gol.CellEdit{Cell: born, Alive: true}
+				edits <- gol.CellEdit{Cell: born, Alive: true} //already alive, so no flip
+				edits <- gol.CellEdit{Cell: killed, Alive: false}
+			}
+		case gol.CellFlipped:
+			//the image's cells are flipped before pausing, after that only edits flip cells
+			if paused {
+				flipped = append(flipped, e.Cell)
+				if len(flipped) == 2 {
+					keyPresses <- 'q'
+				}
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(flipped) != 2 || flipped[0] != born || flipped[1] != killed {
+		t.Errorf("expected CellFlipped for %v then %v, got %v", born, killed, flipped)
+	}
+	expected := append([]util.Cell{born}, initial[1:]...)
+	assertEqualBoard(t, final, expected, p)
+	saved := readAliveCells(fmt.Sprintf("out/%vx%vx0.pgm", p.ImageWidth, p.ImageHeight), p.ImageWidth, p.ImageHeight)
+	assertEqualBoard(t, saved, expected, p)
+}
+
+// TestEditAfterCycle checks that editing the board after it was found to repeat doesn't start looking for repeats again,
+// as CycleDetected is only sent once.
+func TestEditAfterCycle(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1}
+	//a lone cell well away from the glider dies straight away, leaving the glider as it was
+	lone := util.Cell{X: 12, Y: 12}
+
+	keyPresses := make(chan rune, 10)
+	edits := make(chan gol.CellEdit, 10)
+
+	cycles := 0
+	loneFlips := 0
+	quitAt := -1
+	_, err := runGameWithEdits(p, keyPresses, edits, func(event gol.Event) {
+		switch e := event.(type) {
+		case gol.CycleDetected:
+			cycles++
+			if cycles == 1 {
+				edits <- gol.CellEdit{Cell: lone, Alive: true}
+			}
+		case gol.CellFlipped:
+			if e.Cell == lone {
+				loneFlips++
+			}
+		case gol.TurnComplete:
+			//once the lone cell has been born and died, give the glider's period of 64 turns plenty of time to repeat
+			if loneFlips == 2 && quitAt < 0 {
+				quitAt = e.CompletedTurns + 100
+			}
+			if e.CompletedTurns == quitAt {
+				keyPresses <- 'q'
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loneFlips != 2 {
+		t.Errorf("expected the edited cell to be born and die, it flipped %v times", loneFlips)
+	}
+	if cycles != 1 {
+		t.Errorf("expected 1 CycleDetected, got %v", cycles)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestMissingImage checks that an image that doesn't exist gives an ErrorEvent and an error from
+// gol.Run instead of a panic, and that the events channel is still closed.
+func TestMissingImage(t *testing.T) {
+	p := gol.Params{Turns: 10, Threads: 2, ImageWidth: 17, ImageHeight: 17}
+	var errorEvents []gol.ErrorEvent
+	_, err := runGame(p, func(event gol.Event) {
+		switch e := event.(type) {
+		case gol.ErrorEvent:
+			errorEvents = append(errorEvents, e)
+		case gol.FinalTurnComplete:
+			t.Error("expected no FinalTurnComplete when the image can't be read")
+		}
+	})
+	if err == nil {
+		t.Fatal("expected gol.Run to return an error for a missing image")
+	}
+	if len(errorEvents) != 1 || errorEvents[0].Err != err {
+		t.Errorf("expected one ErrorEvent with %v, got %v", err, errorEvents)
+	}
+}
+
+// TestUnwritableImage checks that an image that can't be saved gives an ErrorEvent instead of
+// ImageOutputComplete, and that the game still finishes and returns the error.
+func TestUnwritableImage(t *testing.T) {
+	//a directory where the image should go stops it being created
+	path := "out/16x16x0.pgm"
+	os.Remove(path)
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(path)
+
+	p := gol.Params{Turns: 0, Threads: 2, ImageWidth: 16, ImageHeight: 16}
+	errorEvents := 0
+	final := false
+	_, err := runGame(p, func(event gol.Event) {
+		switch event.(type) {
+		case gol.ErrorEvent:
+			errorEvents++
+		case gol.ImageOutputComplete:
+			t.Error("expected no ImageOutputComplete when the image can't be written")
+		case gol.FinalTurnComplete:
+			final = true
+		}
+	})
+	if err == nil {
+		t.Error("expected gol.Run to return an error for an unwritable image")
+	}
+	if errorEvents != 1 {
+		t.Errorf("expected 1 ErrorEvent, got %v", errorEvents)
+	}
+	if !final {
+		t.Error("expected FinalTurnComplete even though the image couldn't be written")
+	}
+}
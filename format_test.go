@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestFormats checks that images saved in every format can be read back in as the starting world,
+// and that the packed format keeps the turn, rule and topology.
+func TestFormats(t *testing.T) {
+	expected := readAliveCells("check/images/64x64x100.pgm", 64, 64)
+	for _, format := range gol.Formats {
+		t.Run(format, func(t *testing.T) {
+			p := gol.Params{Turns: 100, Threads: 4, ImageWidth: 64, ImageHeight: 64, Format: format}
+			if _, err := runGame(p, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			path := fmt.Sprintf("out/64x64x100.%v", format)
+			file, err := os.Open(path)
+			util.Check(err)
+			defer file.Close()
+			info, _, err := gol.DecodeImage(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Width != 64 || info.Height != 64 {
+				t.Errorf("expected a 64x64 image, got %vx%v", info.Width, info.Height)
+			}
+			if format == gol.FormatPacked && (info.Turn != 100 || info.Rule != gol.DefaultRule || info.Topology != gol.TopologyTorus) {
+				t.Errorf("expected turn 100, rule %v and topology %v, got %+v", gol.DefaultRule, gol.TopologyTorus, info)
+			}
+
+			//start a game of 0 turns from the saved image, which should give it straight back
+			_, err = file.Seek(0, 0)
+			util.Check(err)
+			p = gol.Params{Turns: 0, Threads: 4, ImageWidth: 64, ImageHeight: 64, InitialImage: file}
+			alive, err := runGame(p, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEqualBoard(t, alive, expected, p)
+		})
+	}
+
+	if _, err := runGame(gol.Params{Turns: 0, Threads: 1, ImageWidth: 16, ImageHeight: 16, Format: "bmp"}, nil); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+// TestCorruptHeader checks that images claiming to be huge are rejected from their header alone,
+// rather than the game trying to make room for them.
+func TestCorruptHeader(t *testing.T) {
+	packed := func(width, height uint32, metadata uint16) string {
+		var b bytes.Buffer
+		b.WriteString("GOLP\x01")
+		util.Check(binary.Write(&b, binary.BigEndian, []uint32{width, height}))
+		util.Check(binary.Write(&b, binary.BigEndian, []int64{0, 0}))
+		util.Check(binary.Write(&b, binary.BigEndian, metadata))
+		return b.String()
+	}
+	tests := map[string]string{
+		"huge pgm":         "P5 2000000000 2000000000 255\n",
+		"overflowing pgm":  "P5 99999999999999999 99999999999999999 255\n",
+		"huge packed":      packed(4000000000, 4000000000, 0),
+		"wrong size":       packed(16, 2000000, 0),
+		"long metadata":    packed(16, 16, 65535),
+		"truncated packed": packed(16, 16, 0),
+	}
+	for name, header := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := gol.Params{Turns: 0, Threads: 1, ImageWidth: 16, ImageHeight: 16, InitialImage: strings.NewReader(header)}
+			if _, err := runGame(p, nil); err == nil {
+				t.Error("expected an error starting from the image")
+			}
+			if name != "wrong size" {
+				if _, _, err := gol.DecodeImage(strings.NewReader(header)); err == nil {
+					t.Error("expected an error decoding the image")
+				}
+			}
+		})
+	}
+}
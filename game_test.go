@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestGame checks that stepping a gol.Game gives the same boards as gol.Run, for any number of threads.
+func TestGame(t *testing.T) {
+	for _, threads := range []int{1, 3, 8} {
+		p := gol.Params{Threads: threads, ImageWidth: 64, ImageHeight: 64}
+		world := make([][]byte, p.ImageHeight)
+		for y := range world {
+			world[y] = make([]byte, p.ImageWidth)
+		}
+		for _, cell := range readAliveCells("images/64x64.pgm", p.ImageWidth, p.ImageHeight) {
+			world[cell.Y][cell.X] = 0xFF
+		}
+
+		game, err := gol.New(p, world)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, turns := range []int{1, 100} {
+			game.Step(turns - game.Turn())
+			if game.Turn() != turns {
+				t.Errorf("expected turn %v, got %v", turns, game.Turn())
+			}
+			expected := readAliveCells(fmt.Sprintf("check/images/64x64x%v.pgm", turns), p.ImageWidth, p.ImageHeight)
+			p.Turns = turns
+			assertEqualBoard(t, game.AliveCells(), expected, p)
+		}
+	}
+}
+
+// TestGameSet checks that Set changes the world seen by World and by the next Step.
+func TestGameSet(t *testing.T) {
+	world := make([][]byte, 5)
+	for y := range world {
+		world[y] = make([]byte, 5)
+	}
+	game, err := gol.New(gol.Params{Threads: 2}, world)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//a blinker, which turns from horizontal to vertical
+	for x := 1; x <= 3; x++ {
+		game.Set(x, 2, true)
+	}
+	game.Set(-1, 7, true)
+	if len(game.AliveCells()) != 3 || game.World()[2][1] != 0xFF {
+		t.Fatalf("expected the three cells set to be alive, got %v", game.AliveCells())
+	}
+	game.Step(1)
+	world = game.World()
+	for y := 1; y <= 3; y++ {
+		if world[y][2] != 0xFF {
+			t.Errorf("expected cell (2, %v) to be alive after a turn, got %v", y, game.AliveCells())
+		}
+	}
+	if len(game.AliveCells()) != 3 {
+		t.Errorf("expected 3 alive cells after a turn, got %v", game.AliveCells())
+	}
+
+	if _, err := gol.New(gol.Params{Rule: "X1"}, world); err == nil {
+		t.Error("expected an error for an invalid rule")
+	}
+}
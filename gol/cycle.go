@@ -0,0 +1,114 @@
+package gol
+
+// cycleHistory is how many past generations are remembered when looking for a repeat.
+// It comfortably covers common oscillators and a glider crossing a 512x512 torus (period 2048).
+const cycleHistory = 4096
+
+// FNV-1a, written out so each worker can hash its own band of rows as it finishes them.
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// cycleDetector remembers a hash of each recent generation so that a repeated world,
+// and therefore a still life (period 1) or an oscillator, can be spotted.
+// A repeated hash is only a candidate: it is confirmed with the cells flipped since the turn
+// it was first seen, which are kept in a history.
+type cycleDetector struct {
+	seen   map[uint64]int // hash -> turn it was last seen at
+	hashes []uint64       // ring buffer of the last cycleHistory hashes, indexed by turn
+	flips  *history       // the cells flipped to reach each remembered turn
+}
+
+// newCycleDetector starts looking for repeats from the given world.
+func newCycleDetector(p Params, world [][]byte, turn int) *cycleDetector {
+	d := &cycleDetector{
+		seen:   make(map[uint64]int),
+		hashes: make([]uint64, cycleHistory),
+		flips:  newHistory(cycleHistory, turn),
+	}
+	d.remember(hashWorld(p, world), turn)
+	return d
+}
+
+// check records the world for the given turn, with hash from the workers' hashBands and the cells that flipped to reach it.
+// If the world is exactly the same as it was an earlier turn, it returns that turn and true.
+func (d *cycleDetector) check(hash uint64, flips []int32, turn int) (int, bool) {
+	d.flips.push(turn, flips)
+	if firstSeen, ok := d.seen[hash]; ok && d.unchangedSince(firstSeen) {
+		return firstSeen, true
+	}
+	//a new world, or a hash that collided with a different one, either way it is looked for from now on
+	d.remember(hash, turn)
+	return 0, false
+}
+
+// remember adds the hash of a turn's world, forgetting the generation falling out of the ring buffer.
+func (d *cycleDetector) remember(hash uint64, turn int) {
+	slot := turn % cycleHistory
+	if turn >= cycleHistory {
+		old := d.hashes[slot]
+		if d.seen[old] == turn-cycleHistory {
+			delete(d.seen, old)
+		}
+	}
+	d.hashes[slot] = hash
+	d.seen[hash] = turn
+}
+
+// unchangedSince reports whether every cell has flipped an even number of times since the given turn,
+// so the world is exactly as it was then. Turns whose flips have been forgotten can't be confirmed.
+func (d *cycleDetector) unchangedSince(turn int) bool {
+	if turn < d.flips.oldest {
+		return false
+	}
+	odd := make(map[int32]bool)
+	for t := turn + 1; t <= d.flips.newest; t++ {
+		for _, i := range d.flips.entries[t%len(d.flips.entries)] {
+			if odd[i] {
+				delete(odd, i)
+			} else {
+				odd[i] = true
+			}
+		}
+	}
+	return len(odd) == 0
+}
+
+// hashRow adds a row of cells to an FNV-1a hash.
+func hashRow(hash uint64, row []byte) uint64 {
+	for _, cell := range row {
+		hash ^= uint64(cell)
+		hash *= fnvPrime
+	}
+	return hash
+}
+
+// hashBands combines the hash of each worker's band of rows into the hash of the whole world.
+func hashBands(stats []workerStats) uint64 {
+	hash := uint64(fnvOffset)
+	for i := range stats {
+		for shift := uint(0); shift < 64; shift += 8 {
+			hash ^= (stats[i].hash >> shift) & 0xFF
+			hash *= fnvPrime
+		}
+	}
+	return hash
+}
+
+// hashWorld hashes a world the same way the workers and hashBands do, for worlds that didn't come from the workers.
+func hashWorld(p Params, world [][]byte) uint64 {
+	workerHeight := p.ImageHeight / p.Threads
+	stats := make([]workerStats, p.Threads)
+	for thread := range stats {
+		yBound := (thread + 1) * workerHeight
+		if thread == p.Threads-1 {
+			yBound = p.ImageHeight
+		}
+		stats[thread].hash = fnvOffset
+		for y := thread * workerHeight; y < yBound; y++ {
+			stats[thread].hash = hashRow(stats[thread].hash, world[y])
+		}
+	}
+	return hashBands(stats)
+}
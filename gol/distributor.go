@@ -1,33 +1,38 @@
 package gol
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"uk.ac.bris.cs/gameoflife/analysis"
 	"uk.ac.bris.cs/gameoflife/util"
 )
 
 type distributorChannels struct {
 	events     chan<- Event
 	ioCommand  chan<- ioCommand
-	ioIdle     <-chan bool
+	ioIdle     <-chan error
+	ioError    <-chan error
 	ioFilename chan<- string
-	ioInput    <-chan uint8
-	ioOutput   chan<- uint8
+	ioInput    <-chan []byte
+	ioOutput   chan<- ioSnapshot
+	edits      <-chan CellEdit
 }
 
 //GOL Logic
-func worker(p Params, c distributorChannels, world, emptyWorld [][]byte, thread, workerHeight, turn int, waitGroup *sync.WaitGroup) {
+func worker(p Params, c distributorChannels, rule *Rule, world, emptyWorld [][]byte, thread, workerHeight, turn int, stats *workerStats, waitGroup *sync.WaitGroup) {
+	stats.reset()
 
 	yBound := (thread + 1) * workerHeight
-	extra := p.ImageHeight % p.Threads
 
 	//fmt.Printf("worker thread %d \n", thread + 1)
 
-	if !isPowOfTwo(p.Threads) {
-		yBound += extra
+	//the last worker also takes the rows left over when the height doesn't split evenly
+	if thread == p.Threads-1 {
+		yBound = p.ImageHeight
 	}
 
 	for y := thread * workerHeight; y < yBound; y++ {
@@ -49,29 +54,35 @@ func worker(p Params, c distributorChannels, world, emptyWorld [][]byte, thread,
 			if yDown < 0 {
 				yDown += p.ImageHeight
 			}
-			count := 0 //count the number of neighbouring live cells
-			count += int(world[yUp][xLeft]) +
-				int(world[yUp][x]) +
-				int(world[yUp][xRight]) +
-				int(world[y][xLeft]) +
-				int(world[y][xRight]) +
-				int(world[yDown][xLeft]) +
-				int(world[yDown][x]) +
-				int(world[yDown][xRight])
-			count /= 255
-
-			if (world[y][x] == 0xFF && count == 2) || (world[y][x] == 0xFF && count == 3) {
-				emptyWorld[y][x] = 0xFF
-			} else if world[y][x] == 0xFF && count < 2 {
-				emptyWorld[y][x] = 0
-			} else if world[y][x] == 0xFF && count > 3 {
-				emptyWorld[y][x] = 0
-			} else if count == 3 {
+			//build the 3x3 neighbourhood index (see Rule), one bit per cell
+			index := int(world[yDown][xLeft]&1) |
+				int(world[yDown][x]&1)<<1 |
+				int(world[yDown][xRight]&1)<<2 |
+				int(world[y][xLeft]&1)<<3 |
+				int(world[y][x]&1)<<4 |
+				int(world[y][xRight]&1)<<5 |
+				int(world[yUp][xLeft]&1)<<6 |
+				int(world[yUp][x]&1)<<7 |
+				int(world[yUp][xRight]&1)<<8
+
+			if rule.next(index) {
 				emptyWorld[y][x] = 0xFF
+				stats.addAlive(x, y)
 			} else {
 				emptyWorld[y][x] = 0
 			}
+			//keep track of the cells that changed for the CellFlipped events and TurnStats
+			if emptyWorld[y][x] != world[y][x] {
+				if world[y][x] == 0 {
+					stats.births++
+				} else {
+					stats.deaths++
+				}
+				stats.flipped = append(stats.flipped, util.Cell{X: x, Y: y})
+			}
 		}
+		//hashed while the row is still in the cache, for spotting repeats
+		stats.hash = hashRow(stats.hash, emptyWorld[y])
 	}
 	waitGroup.Done() //-1 in the wait group
 }
@@ -79,7 +90,7 @@ func worker(p Params, c distributorChannels, world, emptyWorld [][]byte, thread,
 // func to count the number of alive cells
 func countAliveCells(p Params, world [][]byte) int {
 	alive := 0
-	for y := 0; y < p.ImageWidth; y++ {
+	for y := 0; y < p.ImageHeight; y++ {
 		for x := 0; x < p.ImageWidth; x++ {
 			if world[y][x] == 0xFF {
 				alive++
@@ -89,16 +100,28 @@ func countAliveCells(p Params, world [][]byte) int {
 	return alive
 }
 
-//func to output file to a pgm file
+//func to output file to a pgm file. The io goroutine writes a copy of the world, so this doesn't wait for the
+//file, and the io goroutine sends ImageOutputComplete (or an ErrorEvent) once it is written
 func outputFileToPGM(p Params, c distributorChannels, world [][]byte, turn int) {
-	c.ioCommand <- ioOutput
-	c.ioFilename <- strings.Join([]string{strconv.Itoa(p.ImageWidth), strconv.Itoa(p.ImageHeight), strconv.Itoa(turn)}, "x")
-	for y := range world { //send world via output channel byte by byte
-		for x := range world[y] {
-			c.ioOutput <- world[y][x]
-		}
+	snapshot := ioSnapshot{
+		filename: strings.Join([]string{strconv.Itoa(p.ImageWidth), strconv.Itoa(p.ImageHeight), strconv.Itoa(turn)}, "x"),
+		turn:     turn,
+		world:    createSlice(p, p.ImageHeight),
 	}
-	c.events <- ImageOutputComplete{turn, strings.Join([]string{strconv.Itoa(p.ImageWidth), strconv.Itoa(p.ImageHeight), strconv.Itoa(turn)}, "x")}
+	for y := range world {
+		copy(snapshot.world[y], world[y])
+	}
+	c.ioCommand <- ioOutput
+	c.ioOutput <- snapshot
+}
+
+// func to give up on a game that can't start, after telling the user why
+func abort(c distributorChannels, turn int, err error) error {
+	c.ioCommand <- ioShutdown
+	c.events <- ErrorEvent{turn, err}
+	c.events <- StateChange{turn, Quitting}
+	close(c.events)
+	return err
 }
 
 // func to create an empty 2D slice (world)
@@ -110,8 +133,9 @@ func createSlice(p Params, height int) [][]byte {
 	return newSlice
 }
 
+// func to send a CellFlipped event for every alive cell, so the GUI starts from the loaded image
 func visualiseImage(p Params, c distributorChannels, world [][]byte, turn int) {
-	for y := 0; y < p.ImageWidth; y++ {
+	for y := 0; y < p.ImageHeight; y++ {
 		for x := 0; x < p.ImageWidth; x++ {
 			if world[y][x] == 0xFF {
 				c.events <- CellFlipped{
@@ -123,109 +147,363 @@ func visualiseImage(p Params, c distributorChannels, world [][]byte, turn int) {
 	}
 }
 
-//helper function to det. thread dimensions
-//Since the image HxW are power of two's, it can only be split
-//perfectly if the number of threads are power of two's
-//this part will check if p.Threads is a power of two
-func isPowOfTwo(n int) bool {
-	if n == 0 {
-		return false
-	} else {
-		for n != 0 {
-			if n%2 != 0 {
-				return false
+// func to fill in the initial world, from memory if Params gives one, otherwise from images/ by the io goroutine
+func loadWorld(p Params, c distributorChannels, world [][]byte) error {
+	switch {
+	case p.InitialWorld != nil:
+		if len(p.InitialWorld) != p.ImageHeight {
+			return fmt.Errorf("initial world has %v rows, expected %v", len(p.InitialWorld), p.ImageHeight)
+		}
+		for y, row := range p.InitialWorld {
+			if len(row) != p.ImageWidth {
+				return fmt.Errorf("row %v of the initial world has %v cells, expected %v", y, len(row), p.ImageWidth)
+			}
+			for x, val := range row {
+				if val != 0 {
+					world[y][x] = 0xFF
+				}
+			}
+		}
+		return nil
+	case p.InitialCells != nil:
+		for _, cell := range p.InitialCells {
+			if cell.X < 0 || cell.Y < 0 || cell.X >= p.ImageWidth || cell.Y >= p.ImageHeight {
+				return fmt.Errorf("initial cell %v is outside the %vx%v world", cell, p.ImageWidth, p.ImageHeight)
 			}
-			n = n / 2
+			world[cell.Y][cell.X] = 0xFF
+		}
+		return nil
+	case p.InitialImage != nil:
+		image, err := decodeWorld("initial image", p.InitialImage, p.ImageWidth, p.ImageHeight)
+		if err != nil {
+			return err
 		}
-		return true
+		fillWorld(p, world, image)
+		return nil
 	}
-}
-
-// distributor divides the work between workers and interacts with other goroutines.
-func distributor(p Params, c distributorChannels, keyChan <-chan rune) {
-
-	// TODO: Create a 2D slice to store the world.
-
-	world := createSlice(p, p.ImageHeight)
-	workerHeight := p.ImageHeight / p.Threads // 'split' the work (like in Median Filter lab)
 
 	//request to read in pgm file
 	c.ioCommand <- ioInput
 	//read in the concatenated ImageWidth and ImageHeight and pass it to the channel
 	c.ioFilename <- strings.Join([]string{strconv.Itoa(p.ImageWidth), strconv.Itoa(p.ImageHeight)}, "x")
+	if err := <-c.ioError; err != nil {
+		return err
+	}
 
 	//add values to the 'world' 2D slice
-	for y := 0; y < p.ImageHeight; y++ {
-		for x := 0; x < p.ImageWidth; x++ {
-			val := <-c.ioInput
+	fillWorld(p, world, <-c.ioInput)
+	return nil
+}
+
+// func to copy an image (all the rows one after another) into the world, any value but 0 being alive
+func fillWorld(p Params, world [][]byte, image []byte) {
+	for y := range world {
+		for x, val := range image[y*p.ImageWidth : (y+1)*p.ImageWidth] {
 			if val != 0 {
-				world[y][x] = val
+				world[y][x] = 0xFF
 			}
 		}
 	}
+}
+
+// distributor divides the work between workers and interacts with other goroutines.
+// It returns the first error from reading or writing images, which is also sent as an ErrorEvent.
+// Cancelling ctx stops it after the current turn, like pressing 'q'.
+func distributor(ctx context.Context, p Params, c distributorChannels, keyChan <-chan rune) error {
+
+	// TODO: Create a 2D slice to store the world.
+
+	rule, err := ParseRule(p.Rule)
+	if err != nil {
+		return abort(c, 0, err)
+	}
+	if !validFormat(p.Format) {
+		return abort(c, 0, fmt.Errorf("unknown image format %q, expected one of %v", p.Format, Formats))
+	}
+
+	world := createSlice(p, p.ImageHeight)
+	workerHeight := p.ImageHeight / p.Threads // 'split' the work (like in Median Filter lab)
+
+	if err := loadWorld(p, c, world); err != nil {
+		return abort(c, 0, err)
+	}
 
 	turn := 0
-	ticker := time.NewTicker(2 * time.Second) //create a new ticker
+	reports := newAliveReporter(p) //sends AliveCellsCount on a timer, every few turns or on request
+	defer reports.stop()
+	alive := countAliveCells(p, world)
 	var aliveCells []util.Cell
 	updateWorld := createSlice(p, p.ImageHeight)
+	stats := make([]workerStats, p.Threads)    //one per worker, filled in every turn
+	cycles := newCycleDetector(p, world, turn) //remembers recent generations to spot still lifes and oscillators
 	// TODO: Execute all turns of the Game of Life.
 
-	if p.Turns != 0 {
-		for t := 0; t < p.Turns; t++ {
+	//visualize the initial world, after this only the cells that change are sent
+	visualiseImage(p, c, world, turn)
 
-			//SDL logic
-			select {
-			case k := <-keyChan: //this bit will take in the key presses and do what it's supposed to do
-				if k == 's' {
-					outputFileToPGM(p, c, world, turn)
-				} else if k == 'q' {
-					outputFileToPGM(p, c, world, turn)
-					c.events <- StateChange{turn, Quitting}
-					return
-				} else if k == 'p' {
+	state := Executing
+	step := false                       //set by 'n' to evolve exactly one turn while paused
+	speed := newThrottle(p.TargetTPS)   //holds turns back to a target rate, changed with '+' and '-'
+	past := newHistory(p.History, turn) //recent turns that can be stepped back through with 'b' and 'f'
+	var flips []int32                   //this turn's flipped cells, for the history and cycles
+
+	//moves the world to an earlier or later turn from the history, flipping the cells that differ
+	moveInHistory := func(cells []int32, newTurn int) {
+		turn = newTurn
+		for _, i := range cells {
+			x, y := int(i)%p.ImageWidth, int(i)/p.ImageWidth
+			world[y][x] = ^world[y][x]
+			if world[y][x] != 0 {
+				alive++
+			} else {
+				alive--
+			}
+			c.events <- CellFlipped{turn, util.Cell{X: x, Y: y}}
+		}
+		c.events <- TurnComplete{turn}
+		//the generations remembered so far may now be in the future
+		if cycles != nil {
+			cycles = newCycleDetector(p, world, turn)
+		}
+	}
+
+	//changes the state of execution and lets the user know
+	changeState := func(newState State) {
+		state = newState
+		c.events <- StateChange{turn, state}
+	}
+
+	//SDL logic: this bit will take in the key presses and do what it's supposed to do.
+	//Every key works in every state, apart from 'n', 'b' and 'f' which only step while paused.
+	handleKey := func(k rune) {
+		switch k {
+		case 's':
+			outputFileToPGM(p, c, world, turn)
+		case 'c':
+			c.events <- Census{turn, analysis.Census(world)}
+		case 'a':
+			reports.send(c, turn, alive)
+		case 'q':
+			//the final state is reported after the loop, like when all turns are done
+			outputFileToPGM(p, c, world, turn)
+			state = Quitting
+		case 'p':
+			if state == Paused {
+				if !p.Quiet {
+					fmt.Println("Continuing....")
+				}
+				changeState(Executing)
+			} else {
+				if !p.Quiet {
 					fmt.Printf("Current turn : %d \n", turn)
-					c.events <- StateChange{turn, Paused}
-					for {
-						kp := <-keyChan
-						if kp == 'p' {
-							fmt.Println("Continuing....")
-							c.events <- StateChange{turn, Executing}
-							break
-						}
-					}
 				}
-			//AliveCell logic
-			case <-ticker.C: //this bit will update AliveCellCount every 2 seconds
-				alive := 0
-				alive += countAliveCells(p, world)
-				if turn != 0 {
-					c.events <- AliveCellsCount{turn, alive}
-				} else {
-					break
+				changeState(Paused)
+			}
+		case 'n':
+			if state == Paused {
+				step = true
+			}
+		case 'b':
+			if state == Paused {
+				if cells, ok := past.back(); ok {
+					moveInHistory(cells, turn-1)
+				}
+			}
+		case 'f':
+			if state == Paused {
+				if cells, ok := past.forward(); ok {
+					moveInHistory(cells, turn+1)
 				}
+			}
+		case '+':
+			speed.faster()
+			if !p.Quiet {
+				fmt.Println("Target speed:", speed)
+			}
+		case '-':
+			speed.slower()
+			if !p.Quiet {
+				fmt.Println("Target speed:", speed)
+			}
+		}
+	}
+
+	//cells changed by the user, e.g. clicked in the SDL window
+	handleEdit := func(edit CellEdit) {
+		x, y := edit.Cell.X, edit.Cell.Y
+		if x < 0 || y < 0 || x >= p.ImageWidth || y >= p.ImageHeight {
+			return
+		}
+		var val byte
+		if edit.Alive {
+			val = 0xFF
+		}
+		if world[y][x] == val {
+			return
+		}
+		world[y][x] = val
+		if edit.Alive {
+			alive++
+		} else {
+			alive--
+		}
+		c.events <- CellFlipped{turn, edit.Cell}
+		past.record(int32(y*p.ImageWidth + x))
+		//earlier generations no longer predict what happens next
+		if cycles != nil {
+			cycles = newCycleDetector(p, world, turn)
+		}
+	}
+
+	//the context was cancelled, so stop as if 'q' was pressed, saving only if asked to
+	handleCancel := func() {
+		if p.SaveOnCancel {
+			outputFileToPGM(p, c, world, turn)
+		}
+		state = Quitting
+	}
+
+	//AliveCell logic: this bit will update AliveCellCount every 2 seconds, even while paused
+	handleTick := func() {
+		if turn != 0 {
+			reports.send(c, turn, alive)
+		}
+	}
+
+	for turn < p.Turns && state != Quitting {
+		if state == Paused && !step {
+			//nothing to compute, so block until something happens
+			select {
+			case k := <-keyChan:
+				handleKey(k)
+			case edit := <-c.edits:
+				handleEdit(edit)
+			case <-reports.tick():
+				handleTick()
+			case <-ctx.Done():
+				handleCancel()
+			}
+			continue
+		}
+
+		if step {
+			//a single step goes ahead without looking at any more keys
+			step = false
+		} else {
+			select {
+			case k := <-keyChan:
+				handleKey(k)
+			case <-reports.tick():
+				handleTick()
+			case <-ctx.Done():
+				handleCancel()
 			default:
 				break
 			}
+			//apply every waiting edit, so drawing keeps up even when turns are slow
+			for pending := true; pending; {
+				select {
+				case edit := <-c.edits:
+					handleEdit(edit)
+				default:
+					pending = false
+				}
+			}
+			//the key might have paused or quit
+			if state != Executing {
+				continue
+			}
+			//keep to the target speed, still answering keys while waiting
+			if wait := speed.untilNextTurn(); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case k := <-keyChan:
+					handleKey(k)
+				case edit := <-c.edits:
+					handleEdit(edit)
+				case <-reports.tick():
+					handleTick()
+				case <-ctx.Done():
+					handleCancel()
+				case <-timer.C:
+				}
+				timer.Stop()
+				continue
+			}
+		}
+		speed.started()
 
-			//visualize
-			visualiseImage(p, c, world, turn)
-			//BASELINE GOL LOGIC
-			var wg = sync.WaitGroup{} //used to make sure all goroutines have done executing before resuming
-			//start := time.Now()
-			for i := 0; i < p.Threads; i++ { //for each thread make the worker work??
-				wg.Add(1) //add number of threads the wait group needs to wait
-				go worker(p, c, world, updateWorld, i, workerHeight, turn, &wg)
+		//BASELINE GOL LOGIC
+		var wg = sync.WaitGroup{} //used to make sure all goroutines have done executing before resuming
+		//start := time.Now()
+		for i := 0; i < p.Threads; i++ { //for each thread make the worker work??
+			wg.Add(1) //add number of threads the wait group needs to wait
+			go worker(p, c, &rule, world, updateWorld, i, workerHeight, turn, &stats[i], &wg)
+		}
+
+		//wait till all goroutines is done (wg == 0), still sending timed reports if the turn is slow
+		workersDone := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(workersDone)
+		}()
+		for waiting := true; waiting; {
+			select {
+			case <-workersDone:
+				waiting = false
+			case <-reports.tick():
+				handleTick()
 			}
+		}
+		//elapsed := time.Since(start)
+		//fmt.Printf("time : %s, turn : %d @ C=%d\n", elapsed, turn, p.Threads)
+		turn++
+		//visualize the cells that changed this turn
+		for i := range stats {
+			for _, cell := range stats[i].flipped {
+				c.events <- CellFlipped{turn, cell}
+			}
+		}
+		alive = 0
+		for i := range stats {
+			alive += stats[i].alive
+		}
+		if p.Stats {
+			c.events <- turnStats(p, turn, stats)
+		}
+		c.events <- TurnComplete{turn}
+		if p.History > 0 || cycles != nil {
+			flips = flips[:0]
+			for i := range stats {
+				for _, cell := range stats[i].flipped {
+					flips = append(flips, int32(cell.Y*p.ImageWidth+cell.X))
+				}
+			}
+		}
+		//carrying on from a rewound turn replaces the turns that came after it
+		if p.History > 0 {
+			past.push(turn, flips)
+		}
+		if reports.due(turn) {
+			reports.send(c, turn, alive)
+		}
+		//update the 2D world slice
+		tmp := world
+		world = updateWorld
+		updateWorld = tmp
 
-			wg.Wait() //wait till all goroutines is done (wg == 0)
-			//elapsed := time.Since(start)
-			//fmt.Printf("time : %s, turn : %d @ C=%d\n", elapsed, turn, p.Threads)
-			turn = t + 1
-			c.events <- TurnComplete{turn}
-			//update the 2D world slice
-			tmp := world
-			world = updateWorld
-			updateWorld = tmp
+		//check if this world has been seen before, only the first repeat is reported
+		if cycles != nil {
+			if firstSeen, found := cycles.check(hashBands(stats), flips, turn); found {
+				period := turn - firstSeen
+				c.events <- CycleDetected{turn, period, firstSeen}
+				cycles = nil
+				if p.FastForward {
+					//every whole period leaves the world as it is now, so jump over them
+					turn += (p.Turns - turn) / period * period
+					past.reset(turn)
+				}
+			}
 		}
 	}
 
@@ -236,6 +514,10 @@ func distributor(p Params, c distributorChannels, keyChan <-chan rune) {
 
 	// TODO: Report the final state using FinalTurnCompleteEvent.
 
+	if p.Census {
+		c.events <- Census{turn, analysis.Census(world)}
+	}
+
 	// go through the 'world' and append cells that are still alive
 	for y := 0; y < p.ImageHeight; y++ {
 		for x := 0; x < p.ImageWidth; x++ {
@@ -245,15 +527,20 @@ func distributor(p Params, c distributorChannels, keyChan <-chan rune) {
 		}
 	}
 
+	// Make sure that the Io has finished any output before exiting, the answer is the first image that failed.
+	// Waiting before FinalTurnComplete means its ImageOutputComplete (or ErrorEvent) comes first,
+	// so visualisers that stop at FinalTurnComplete still see it.
+	c.ioCommand <- ioCheckIdle
+	ioErr := <-c.ioIdle
+
 	// put FinalTurnComplete into events channel
 	c.events <- FinalTurnComplete{turn, aliveCells}
 
-	// Make sure that the Io has finished any output before exiting.
-	c.ioCommand <- ioCheckIdle
-	<-c.ioIdle
+	c.ioCommand <- ioShutdown
 
-	c.events <- StateChange{turn, Quitting}
+	changeState(Quitting)
 
 	// Close the channel to stop the SDL goroutine gracefully. Removing may cause deadlock.
 	close(c.events)
+	return ioErr
 }
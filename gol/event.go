@@ -2,6 +2,9 @@ package gol
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+
 	"uk.ac.bris.cs/gameoflife/util"
 )
 
@@ -15,14 +18,18 @@ type Event interface {
 }
 
 // AliveCellsCount is an Event notifying the user about the number of currently alive cells.
-// This Event should be sent every 2s.
+// This Event should be sent every 2s (or every Params.ReportInterval), every Params.ReportTurns turns
+// and whenever 'a' is pressed. TurnsPerSecond is the throughput since the previous AliveCellsCount.
 type AliveCellsCount struct { // implements Event
 	CompletedTurns int
 	CellsCount     int
+	TurnsPerSecond float64
 }
 
 // ImageOutputComplete is an Event notifying the user about the completion of output.
 // This Event should be sent every time an image has been saved.
+// It is sent once the file has been written and synced, which may be after later turns have completed,
+// but always before FinalTurnComplete.
 type ImageOutputComplete struct { // implements Event
 	CompletedTurns int
 	Filename       string
@@ -59,6 +66,46 @@ type TurnComplete struct { // implements Event
 	CompletedTurns int
 }
 
+// CycleDetected is an Event notifying the user that the world has started repeating.
+// The world after CompletedTurns is identical to the world after FirstSeenTurn, so it
+// will keep repeating every Period turns. A Period of 1 means the world is a still life.
+// This Event is sent at most once per run.
+type CycleDetected struct { // implements Event
+	CompletedTurns int
+	Period         int
+	FirstSeenTurn  int
+}
+
+// TurnStats is an Event describing how the board changed during a turn.
+// Bands holds the number of alive cells in each worker's band of rows, from the top of the board down.
+// Min and Max are the corners of the bounding box of the alive cells, both zero if no cells are alive.
+// Activity is a heatmap of the board split into regions, counting the cells that flipped in each one.
+// This Event is sent after every turn, just before TurnComplete, if Params.Stats is set.
+type TurnStats struct { // implements Event
+	CompletedTurns int
+	Alive          int
+	Births         int
+	Deaths         int
+	Density        float64
+	Bands          []int
+	Min, Max       util.Cell
+	Activity       [][]int
+}
+
+// Census is an Event reporting how many of each known object (block, blinker, glider...) are on the board.
+// This Event is sent when 'c' is pressed, and before FinalTurnComplete if Params.Census is set.
+type Census struct { // implements Event
+	CompletedTurns int
+	Objects        map[string]int
+}
+
+// ErrorEvent is an Event notifying the user that an image couldn't be read or written.
+// If the initial image can't be read, the game stops straight after this Event.
+type ErrorEvent struct { // implements Event
+	CompletedTurns int
+	Err            error
+}
+
 // FinalTurnComplete is an Event notifying the testing framework about the new world state after execution finished.
 // The data included with this Event is used directly by the tests.
 // SDL closes the window when this Event is sent.
@@ -91,13 +138,21 @@ func (event StateChange) GetCompletedTurns() int {
 }
 
 func (event AliveCellsCount) String() string {
-	return fmt.Sprintf("Alive Cells %v", event.CellsCount)
+	return fmt.Sprintf("Alive Cells %v (%.1f turns/s)", event.CellsCount, event.TurnsPerSecond)
 }
 
 func (event AliveCellsCount) GetCompletedTurns() int {
 	return event.CompletedTurns
 }
 
+func (event ErrorEvent) String() string {
+	return fmt.Sprintf("Error: %v", event.Err)
+}
+
+func (event ErrorEvent) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
 func (event ImageOutputComplete) String() string {
 	return fmt.Sprintf("File %v output complete", event.Filename)
 }
@@ -122,6 +177,39 @@ func (event TurnComplete) GetCompletedTurns() int {
 	return event.CompletedTurns
 }
 
+func (event CycleDetected) String() string {
+	return fmt.Sprintf("Cycle of period %v detected, first seen at turn %v", event.Period, event.FirstSeenTurn)
+}
+
+func (event CycleDetected) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event TurnStats) String() string {
+	return fmt.Sprintf("")
+}
+
+func (event TurnStats) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
+func (event Census) String() string {
+	names := make([]string, 0, len(event.Objects))
+	for name := range event.Objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	counts := make([]string, len(names))
+	for i, name := range names {
+		counts[i] = fmt.Sprintf("%v %v", event.Objects[name], name)
+	}
+	return fmt.Sprintf("Census: %v", strings.Join(counts, ", "))
+}
+
+func (event Census) GetCompletedTurns() int {
+	return event.CompletedTurns
+}
+
 func (event FinalTurnComplete) String() string {
 	return fmt.Sprintf("")
 }
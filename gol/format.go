@@ -0,0 +1,275 @@
+package gol
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// The formats images can be saved in, chosen with Params.Format. Each one is also the file extension.
+// Any of them can be read back in, the format is recognised from the start of the file.
+const (
+	FormatPGM    = "pgm"    // a binary pgm image, one byte per cell (the default)
+	FormatPGMGz  = "pgm.gz" // a gzip compressed pgm image
+	FormatPacked = "golp"   // one bit per cell, with the turn, rule, seed and topology the world came from
+)
+
+// Formats lists every format in the order they are looked for in images/.
+var Formats = []string{FormatPGM, FormatPGMGz, FormatPacked}
+
+// TopologyTorus is the only topology so far: the edges of the world wrap around to the opposite edge.
+const TopologyTorus = "torus"
+
+// packedMagic starts every file in the packed format, followed by packedVersion.
+const packedMagic = "GOLP"
+const packedVersion = 1
+
+// MaxImageCells is the most cells an image can have, so a corrupt header can't ask for more memory than that.
+const MaxImageCells = 1 << 28
+
+// maxMetadata is the longest rule or topology the packed format will read.
+const maxMetadata = 256
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ImageInfo describes a saved world. Pgm images only have a size, the rest is only kept by FormatPacked.
+type ImageInfo struct {
+	Width, Height int
+	Turn          int    // completed turns when the world was saved
+	Rule          string // the rule the world was evolving under
+	Seed          int64  // the seed a random starting world was made from, 0 if it was loaded from a file
+	Topology      string // how the edges of the world join up, TopologyTorus
+}
+
+// validFormat reports whether format is one of Formats, or empty for FormatPGM.
+func validFormat(format string) bool {
+	if format == "" {
+		return true
+	}
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeImage writes the world (rows of 0 for dead and 0xFF for alive) to w in the given format.
+// The size of the world is taken from info.
+func EncodeImage(w io.Writer, format string, info ImageInfo, world [][]byte) error {
+	switch format {
+	case FormatPGM, "":
+		writer := bufio.NewWriter(w)
+		_, _ = writer.WriteString("P5\n" + strconv.Itoa(info.Width) + " " + strconv.Itoa(info.Height) + "\n" + strconv.Itoa(255) + "\n")
+		for y := range world {
+			_, _ = writer.Write(world[y])
+		}
+		//a bufio.Writer keeps the first error, so it's enough to check it here
+		return writer.Flush()
+	case FormatPGMGz:
+		compressed := gzip.NewWriter(w)
+		if err := EncodeImage(compressed, FormatPGM, info, world); err != nil {
+			return err
+		}
+		return compressed.Close()
+	case FormatPacked:
+		return encodePacked(w, info, world)
+	default:
+		return fmt.Errorf("unknown image format %q", format)
+	}
+}
+
+// DecodeImage reads a world in any of the Formats, returning the cells row after row
+// with 0 for dead and 0xFF for alive. Images with more than MaxImageCells cells are rejected.
+func DecodeImage(r io.Reader) (ImageInfo, []byte, error) {
+	return decodeImage(r, 0, 0)
+}
+
+// decodeImage reads a world like DecodeImage. If width and height aren't 0 an image of any other size
+// is rejected as soon as its header is read, before the cells are.
+func decodeImage(r io.Reader, width, height int) (ImageInfo, []byte, error) {
+	reader := bufio.NewReader(r)
+	start, _ := reader.Peek(len(packedMagic))
+	switch {
+	case bytes.HasPrefix(start, gzipMagic):
+		compressed, err := gzip.NewReader(reader)
+		if err != nil {
+			return ImageInfo{}, nil, err
+		}
+		defer compressed.Close()
+		return decodeImage(compressed, width, height)
+	case string(start) == packedMagic:
+		return decodePacked(reader, width, height)
+	default:
+		return decodePgm(reader, width, height)
+	}
+}
+
+// CheckSize makes sure the size from an image header is what was expected (if width and height aren't 0),
+// and no more than MaxImageCells, before anything that size is allocated.
+func CheckSize(info ImageInfo, width, height int) error {
+	if info.Width <= 0 || info.Height <= 0 || info.Width > MaxImageCells/info.Height {
+		return fmt.Errorf("bad size %vx%v, at most %v cells are allowed", info.Width, info.Height, MaxImageCells)
+	}
+	if width != 0 && height != 0 && (info.Width != width || info.Height != height) {
+		return fmt.Errorf("incorrect size %vx%v, expected %vx%v", info.Width, info.Height, width, height)
+	}
+	return nil
+}
+
+// decodeWorld reads an image in any format and checks it is the size of the board.
+// name is only used in errors.
+func decodeWorld(name string, r io.Reader, width, height int) ([]byte, error) {
+	_, image, err := decodeImage(r, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", name, err)
+	}
+	return image, nil
+}
+
+// decodePgm reads a binary (P5) pgm image with a maxval of 255.
+func decodePgm(reader *bufio.Reader, width, height int) (ImageInfo, []byte, error) {
+	info := ImageInfo{Topology: TopologyTorus}
+	magic, err := HeaderField(reader)
+	if err != nil || magic != "P5" {
+		return info, nil, errors.New("not a pgm file")
+	}
+	var header [3]int
+	for i := range header {
+		field, err := HeaderField(reader)
+		if err != nil {
+			return info, nil, fmt.Errorf("pgm header: %v", err)
+		}
+		header[i], err = strconv.Atoi(field)
+		if err != nil || header[i] <= 0 || header[i] > MaxImageCells {
+			return info, nil, fmt.Errorf("pgm header: bad number %q", field)
+		}
+	}
+	info.Width, info.Height = header[0], header[1]
+	if err := CheckSize(info, width, height); err != nil {
+		return info, nil, err
+	}
+	if header[2] != 255 {
+		return info, nil, fmt.Errorf("incorrect maxval/bit depth %v", header[2])
+	}
+	//HeaderField has already read the single whitespace character after maxval
+	image := make([]byte, info.Width*info.Height)
+	if _, err := io.ReadFull(reader, image); err != nil {
+		return info, nil, fmt.Errorf("expected %v bytes of image data: %v", len(image), err)
+	}
+	for i, val := range image {
+		if val != 0 {
+			image[i] = 0xFF
+		}
+	}
+	return info, image, nil
+}
+
+// HeaderField reads one whitespace separated field of a pgm or pbm header, skipping '#' comments,
+// and the whitespace character straight after it.
+func HeaderField(reader *bufio.Reader) (string, error) {
+	var field []byte
+	for {
+		c, err := reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case c == '#' && len(field) == 0:
+			if _, err := reader.ReadString('\n'); err != nil {
+				return "", err
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if len(field) > 0 {
+				return string(field), nil
+			}
+		default:
+			field = append(field, c)
+		}
+	}
+}
+
+// packedHeader is the fixed size part of the packed format, after the magic and version.
+// It is followed by the rule and topology (each as a uint16 length and the bytes), then the cells
+// at one bit per cell, row after row with no padding, the first cell in the lowest bit.
+type packedHeader struct {
+	Width, Height uint32
+	Turn          int64
+	Seed          int64
+}
+
+func encodePacked(w io.Writer, info ImageInfo, world [][]byte) error {
+	writer := bufio.NewWriter(w)
+	_, _ = writer.WriteString(packedMagic)
+	_ = writer.WriteByte(packedVersion)
+	header := packedHeader{uint32(info.Width), uint32(info.Height), int64(info.Turn), info.Seed}
+	_ = binary.Write(writer, binary.BigEndian, header)
+	for _, s := range []string{info.Rule, info.Topology} {
+		_ = binary.Write(writer, binary.BigEndian, uint16(len(s)))
+		_, _ = writer.WriteString(s)
+	}
+
+	bits := make([]byte, (info.Width*info.Height+7)/8)
+	i := 0
+	for y := 0; y < info.Height; y++ {
+		for x := 0; x < info.Width; x++ {
+			if world[y][x] != 0 {
+				bits[i/8] |= 1 << uint(i%8)
+			}
+			i++
+		}
+	}
+	_, _ = writer.Write(bits)
+	return writer.Flush()
+}
+
+func decodePacked(reader *bufio.Reader, width, height int) (ImageInfo, []byte, error) {
+	var info ImageInfo
+	start := make([]byte, len(packedMagic)+1)
+	if _, err := io.ReadFull(reader, start); err != nil {
+		return info, nil, err
+	}
+	if version := start[len(packedMagic)]; version != packedVersion {
+		return info, nil, fmt.Errorf("unsupported packed image version %v", version)
+	}
+	var header packedHeader
+	if err := binary.Read(reader, binary.BigEndian, &header); err != nil {
+		return info, nil, fmt.Errorf("packed image header: %v", err)
+	}
+	info = ImageInfo{Width: int(header.Width), Height: int(header.Height), Turn: int(header.Turn), Seed: header.Seed}
+	if err := CheckSize(info, width, height); err != nil {
+		return info, nil, err
+	}
+	for _, s := range []*string{&info.Rule, &info.Topology} {
+		var length uint16
+		if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+			return info, nil, fmt.Errorf("packed image header: %v", err)
+		}
+		if length > maxMetadata {
+			return info, nil, fmt.Errorf("packed image header: %v bytes of metadata, at most %v are allowed", length, maxMetadata)
+		}
+		text := make([]byte, length)
+		if _, err := io.ReadFull(reader, text); err != nil {
+			return info, nil, fmt.Errorf("packed image header: %v", err)
+		}
+		*s = string(text)
+	}
+
+	bits := make([]byte, (info.Width*info.Height+7)/8)
+	if _, err := io.ReadFull(reader, bits); err != nil {
+		return info, nil, fmt.Errorf("expected %v bytes of packed cells: %v", len(bits), err)
+	}
+	image := make([]byte, info.Width*info.Height)
+	for i := range image {
+		if bits[i/8]&(1<<uint(i%8)) != 0 {
+			image[i] = 0xFF
+		}
+	}
+	return info, image, nil
+}
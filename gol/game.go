@@ -0,0 +1,109 @@
+package gol
+
+import (
+	"errors"
+	"sync"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Game is a Game of Life that is stepped directly by its caller instead of through Run's channels.
+// It uses the same workers as Run, p.Threads of them per turn. A Game must only be used by one
+// goroutine at a time.
+type Game struct {
+	p           Params
+	rule        Rule
+	world, next [][]byte
+	stats       []workerStats
+	turn        int
+}
+
+// New creates a game from an initial world, given as rows of cells like a pgm image: 0 for dead
+// and anything else (normally 0xFF) for alive. The world is copied and sets the size of the board,
+// so p.ImageWidth and p.ImageHeight are ignored, as is p.Turns. The rule comes from p.Rule.
+func New(p Params, world [][]byte) (*Game, error) {
+	rule, err := ParseRule(p.Rule)
+	if err != nil {
+		return nil, err
+	}
+	if len(world) == 0 || len(world[0]) == 0 {
+		return nil, errors.New("the world is empty")
+	}
+	p.ImageHeight, p.ImageWidth = len(world), len(world[0])
+	if p.Threads < 1 {
+		p.Threads = 1
+	}
+
+	g := &Game{
+		p:     p,
+		rule:  rule,
+		world: createSlice(p, p.ImageHeight),
+		next:  createSlice(p, p.ImageHeight),
+		stats: make([]workerStats, p.Threads),
+	}
+	for y := range world {
+		if len(world[y]) != p.ImageWidth {
+			return nil, errors.New("the rows of the world are not all the same length")
+		}
+		for x, cell := range world[y] {
+			if cell != 0 {
+				g.world[y][x] = 0xFF
+			}
+		}
+	}
+	return g, nil
+}
+
+// Step evolves the world by n turns.
+func (g *Game) Step(n int) {
+	workerHeight := g.p.ImageHeight / g.p.Threads
+	for i := 0; i < n; i++ {
+		var wg sync.WaitGroup
+		for thread := 0; thread < g.p.Threads; thread++ {
+			wg.Add(1)
+			go worker(g.p, distributorChannels{}, &g.rule, g.world, g.next, thread, workerHeight, g.turn, &g.stats[thread], &wg)
+		}
+		wg.Wait()
+		g.world, g.next = g.next, g.world
+		g.turn++
+	}
+}
+
+// World returns a copy of the current world, with 0xFF for alive cells and 0 for dead ones.
+func (g *Game) World() [][]byte {
+	world := createSlice(g.p, g.p.ImageHeight)
+	for y := range world {
+		copy(world[y], g.world[y])
+	}
+	return world
+}
+
+// AliveCells lists the cells that are currently alive, row by row.
+func (g *Game) AliveCells() []util.Cell {
+	var cells []util.Cell
+	for y := range g.world {
+		for x, cell := range g.world[y] {
+			if cell != 0 {
+				cells = append(cells, util.Cell{X: x, Y: y})
+			}
+		}
+	}
+	return cells
+}
+
+// Set makes a cell alive or dead. Cells outside the world are ignored.
+func (g *Game) Set(x, y int, alive bool) {
+	if x < 0 || y < 0 || x >= g.p.ImageWidth || y >= g.p.ImageHeight {
+		return
+	}
+	if alive {
+		g.world[y][x] = 0xFF
+	} else {
+		g.world[y][x] = 0
+	}
+}
+
+// Turn is the number of turns completed so far.
+func (g *Game) Turn() int {
+	return g.turn
+}
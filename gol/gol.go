@@ -1,39 +1,102 @@
 package gol
 
+import (
+	"context"
+	"io"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
 // Params provides the details of how to run the Game of Life and which image to load.
 type Params struct {
 	Turns       int
 	Threads     int
 	ImageWidth  int
 	ImageHeight int
+	Rule        string // B/S rule string, e.g. "B3/S23" or "B2-a/S12". Empty means DefaultRule.
+	FastForward bool   // skip straight to the final turn once the world is found to repeat
+	Census      bool   // send a Census of the final world before FinalTurnComplete
+	Stats       bool   // send a TurnStats event after every turn
+
+	ReportInterval time.Duration // how often to send AliveCellsCount. Zero means DefaultReportInterval, negative turns it off
+	ReportTurns    int           // also send AliveCellsCount every this many turns, if above zero
+	TargetTPS      float64       // turns per second to run at, 0 means as fast as possible
+	History        int           // how many past turns are kept so they can be rewound while paused
+	SaveOnCancel   bool          // save the world as a pgm if the context given to RunContext is cancelled
+	Format         string        // the format images are saved in, one of Formats. Empty means FormatPGM
+	Quiet          bool          // don't print progress like "File 512x512 input done!" to stdout, the events say the same
+
+	// The world to start from, instead of reading images/<width>x<height>.pgm (or .pgm.gz or .golp).
+	// Only the first one set is used.
+	InitialWorld [][]byte    // rows of cells, 0 for dead and anything else for alive
+	InitialCells []util.Cell // the cells that are alive
+	InitialImage io.Reader   // an image in any of the Formats, read once when the game starts
+}
+
+// CellEdit asks a running Game of Life to make a cell alive or dead, e.g. when it is clicked in the GUI.
+type CellEdit struct {
+	Cell  util.Cell
+	Alive bool
 }
 
 // Run starts the processing of Game of Life. It should initialise channels and goroutines.
-func Run(p Params, events chan<- Event, keyPresses <-chan rune) {
+// It returns the first error from reading or writing an image, which is also sent as an ErrorEvent.
+// If the image can't be read the game doesn't start and there is no FinalTurnComplete.
+func Run(p Params, events chan<- Event, keyPresses <-chan rune) error {
+	return RunWithEdits(p, events, keyPresses, nil)
+}
+
+// RunContext is like Run, but also stops when ctx is cancelled, as if 'q' had been pressed:
+// the world is saved if p.SaveOnCancel is set, FinalTurnComplete and StateChange{Quitting} are sent
+// and events is closed. The goroutines it starts are all stopped before it returns.
+func RunContext(ctx context.Context, p Params, events chan<- Event, keyPresses <-chan rune) error {
+	return runGame(ctx, p, events, keyPresses, nil)
+}
+
+// RunWithEdits is like Run, but the board can also be changed while it runs (or is paused) by sending on edits.
+// Every edit that changes a cell is followed by a CellFlipped event.
+func RunWithEdits(p Params, events chan<- Event, keyPresses <-chan rune, edits <-chan CellEdit) error {
+	return runGame(context.Background(), p, events, keyPresses, edits)
+}
+
+// runGame starts the io goroutine and the distributor, with everything Run and its variants can ask for.
+func runGame(ctx context.Context, p Params, events chan<- Event, keyPresses <-chan rune, edits <-chan CellEdit) error {
 
 	//	TODO: Put the missing channels in here.
 	ioFilename := make(chan string)
-	ioInput := make(chan uint8)
-	ioOutput := make(chan uint8)
+	ioInput := make(chan []byte)
+	ioOutput := make(chan ioSnapshot)
 	ioCommand := make(chan ioCommand)
-	ioIdle := make(chan bool)
+	ioIdle := make(chan error)
+	ioError := make(chan error)
 
 	ioChannels := ioChannels{
 		command:  ioCommand,
 		idle:     ioIdle,
+		errors:   ioError,
+		events:   events,
 		filename: ioFilename,
 		output:   ioOutput,
 		input:    ioInput,
 	}
-	go startIo(p, ioChannels)
+	ioDone := make(chan bool)
+	go func() {
+		startIo(p, ioChannels)
+		close(ioDone)
+	}()
 
 	distributorChannels := distributorChannels{
 		events:     events,
 		ioCommand:  ioCommand,
 		ioIdle:     ioIdle,
+		ioError:    ioError,
 		ioFilename: ioFilename,
 		ioOutput:   ioOutput,
 		ioInput:    ioInput,
+		edits:      edits,
 	}
-	distributor(p, distributorChannels, keyPresses)
+	err := distributor(ctx, p, distributorChannels, keyPresses)
+	<-ioDone
+	return err
 }
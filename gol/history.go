@@ -0,0 +1,93 @@
+package gol
+
+// DefaultHistory is how many past turns can be rewound by default, as long as they fit in historyCells.
+const DefaultHistory = 1000
+
+// historyCells is the most flipped cells the history holds (4 bytes each, so 16MB). On a busy board
+// the oldest turns are forgotten to stay under it, however many turns were asked for.
+const historyCells = 1 << 22
+
+// history is a bounded record of past generations so the board can be rewound while paused.
+// Rather than whole worlds it keeps, for each turn, the cells that flipped to reach it (as y*width+x),
+// so flipping them again goes back a turn. Any turn from oldest to newest can be returned to.
+type history struct {
+	entries                 [][]int32 //ring buffer, entries[t%len(entries)] holds the flips that led to turn t
+	oldest, newest, current int
+	cells                   int //the capacity of all the entries, kept under historyCells
+}
+
+// newHistory keeps up to size turns, starting from the given turn. A size of 0 keeps nothing.
+func newHistory(size, turn int) *history {
+	return &history{entries: make([][]int32, size), oldest: turn, newest: turn, current: turn}
+}
+
+// push records the flips that led to a new turn. Any turns after the current one, left over
+// from rewinding, are forgotten as this starts a new timeline.
+func (h *history) push(turn int, flips []int32) {
+	h.current, h.newest = turn, turn
+	if len(h.entries) == 0 {
+		h.oldest = turn
+		return
+	}
+	slot := turn % len(h.entries)
+	h.cells -= cap(h.entries[slot])
+	h.entries[slot] = append(h.entries[slot][:0], flips...)
+	h.cells += cap(h.entries[slot])
+	if h.newest-h.oldest > len(h.entries) {
+		h.oldest = h.newest - len(h.entries)
+	}
+	h.trim()
+}
+
+// record adds a single flip made by an edit to the current turn, so rewinding past it undoes it too.
+func (h *history) record(index int32) {
+	h.truncate()
+	if h.current == h.oldest {
+		//nothing to go back to before this turn, so it's just part of the starting world
+		return
+	}
+	slot := h.current % len(h.entries)
+	h.cells -= cap(h.entries[slot])
+	h.entries[slot] = append(h.entries[slot], index)
+	h.cells += cap(h.entries[slot])
+	h.trim()
+}
+
+// trim forgets the oldest turns until the history fits in historyCells, always keeping the newest one.
+func (h *history) trim() {
+	for h.cells > historyCells && h.oldest < h.newest-1 {
+		h.oldest++
+		slot := h.oldest % len(h.entries)
+		h.cells -= cap(h.entries[slot])
+		h.entries[slot] = nil
+	}
+}
+
+// back moves to the previous turn, returning the cells to flip to get there.
+func (h *history) back() ([]int32, bool) {
+	if h.current <= h.oldest {
+		return nil, false
+	}
+	flips := h.entries[h.current%len(h.entries)]
+	h.current--
+	return flips, true
+}
+
+// forward moves to the next turn after rewinding, returning the cells to flip to get there.
+func (h *history) forward() ([]int32, bool) {
+	if h.current >= h.newest {
+		return nil, false
+	}
+	h.current++
+	return h.entries[h.current%len(h.entries)], true
+}
+
+// truncate forgets the turns after the current one.
+func (h *history) truncate() {
+	h.newest = h.current
+}
+
+// reset forgets everything, e.g. after fast forwarding over turns that were never recorded.
+func (h *history) reset(turn int) {
+	h.oldest, h.newest, h.current = turn, turn, turn
+}
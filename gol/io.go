@@ -2,29 +2,37 @@ package gol
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
-	"strconv"
-	"strings"
-	"uk.ac.bris.cs/gameoflife/util"
+	"path/filepath"
 )
 
 type ioChannels struct {
 	command <-chan ioCommand
-	idle    chan<- bool
+	idle    chan<- error // answers ioCheckIdle with the first image that couldn't be written, nil if none
+	errors  chan<- error // the result of every ioInput, nil if it worked
+	events  chan<- Event // ImageOutputComplete (or ErrorEvent) once an image is written
 
 	filename <-chan string
-	output   <-chan uint8
-	input    chan<- uint8
+	output   <-chan ioSnapshot
+	input    chan<- []byte // the whole image, row after row
+}
+
+// ioSnapshot is a copy of the world for the io goroutine to write while the distributor carries on.
+type ioSnapshot struct {
+	filename string
+	turn     int
+	world    [][]byte
 }
 
 // ioState is the internal ioState of the io goroutine.
 type ioState struct {
-	params   Params
-	channels ioChannels
+	params      Params
+	channels    ioChannels
+	images      string //the directory images are read from
+	outputError error  //the first image that couldn't be written
 }
 
-// ioCommand allows requesting behaviour from the io (pgm) goroutine.
+// ioCommand allows requesting behaviour from the io (image) goroutine.
 type ioCommand uint8
 
 // This is a way of creating enums in Go.
@@ -32,117 +40,137 @@ type ioCommand uint8
 //		ioOutput 	= 0
 //		ioInput 	= 1
 //		ioCheckIdle = 2
+//		ioShutdown 	= 3
 const (
 	ioOutput ioCommand = iota
 	ioInput
 	ioCheckIdle
+	ioShutdown
 )
 
-// writePgmImage receives an array of bytes and writes it to a pgm file.
-func (io *ioState) writePgmImage() {
+// writeImage receives a snapshot of the world and writes it to out/ in the format from Params.Format.
+// The distributor doesn't wait for it: ImageOutputComplete is sent once the file is synced to disk,
+// or an ErrorEvent if it couldn't be written.
+func (io *ioState) writeImage() {
 	_ = os.Mkdir("out", os.ModePerm)
 
-	// Request a filename from the distributor.
-	filename := <-io.channels.filename
-
-	file, ioError := os.Create("out/" + filename + ".pgm")
-	util.Check(ioError)
-	defer file.Close()
-
-	_, _ = file.WriteString("P5\n")
-	//_, _ = file.WriteString("# PGM file writer by pnmmodules (https://github.com/owainkenwayucl/pnmmodules).\n")
-	_, _ = file.WriteString(strconv.Itoa(io.params.ImageWidth))
-	_, _ = file.WriteString(" ")
-	_, _ = file.WriteString(strconv.Itoa(io.params.ImageHeight))
-	_, _ = file.WriteString("\n")
-	_, _ = file.WriteString(strconv.Itoa(255))
-	_, _ = file.WriteString("\n")
-
-	world := make([][]byte, io.params.ImageHeight)
-	for i := range world {
-		world[i] = make([]byte, io.params.ImageWidth)
-	}
+	// Receive the world (and its filename) from the distributor.
+	snapshot := <-io.channels.output
 
-	for y := 0; y < io.params.ImageHeight; y++ {
-		for x := 0; x < io.params.ImageWidth; x++ {
-			val := <-io.channels.output
-			//if val != 0 {
-			//	fmt.Println(x, y)
-			//}
-			world[y][x] = val
+	ioError := io.saveImage("out/"+snapshot.filename+"."+io.format(), snapshot)
+	if ioError != nil {
+		if io.outputError == nil {
+			io.outputError = ioError
 		}
+		io.channels.events <- ErrorEvent{snapshot.turn, ioError}
+		return
+	}
+	if !io.params.Quiet {
+		fmt.Println("File", snapshot.filename, "output done!")
 	}
+	io.channels.events <- ImageOutputComplete{snapshot.turn, snapshot.filename}
+}
 
-	for y := 0; y < io.params.ImageHeight; y++ {
-		for x := 0; x < io.params.ImageWidth; x++ {
-			_, ioError = file.Write([]byte{world[y][x]})
-			util.Check(ioError)
-		}
+// format is the format images are saved in.
+func (io *ioState) format() string {
+	if io.params.Format == "" {
+		return FormatPGM
 	}
+	return io.params.Format
+}
 
-	ioError = file.Sync()
-	util.Check(ioError)
+// saveImage writes a snapshot to a file and syncs it, returning the first error.
+func (io *ioState) saveImage(path string, snapshot ioSnapshot) error {
+	file, ioError := os.Create(path)
+	if ioError != nil {
+		return ioError
+	}
+	defer file.Close()
 
-	fmt.Println("File", filename, "output done!")
+	rule := io.params.Rule
+	if rule == "" {
+		rule = DefaultRule
+	}
+	info := ImageInfo{
+		Width:    io.params.ImageWidth,
+		Height:   io.params.ImageHeight,
+		Turn:     snapshot.turn,
+		Rule:     rule,
+		Topology: TopologyTorus,
+	}
+	if ioError = EncodeImage(file, io.format(), info, snapshot.world); ioError != nil {
+		return ioError
+	}
+	return file.Sync()
 }
 
-// readPgmImage opens a pgm file and sends its data as an array of bytes.
-func (io *ioState) readPgmImage() {
+// readImage opens an image and sends its data as an array of bytes, all in one message.
+// Whether it could be read is sent on the errors channel first, the bytes only follow if it could.
+func (io *ioState) readImage() {
 
 	// Request a filename from the distributor.
 	filename := <-io.channels.filename
 
-	data, ioError := ioutil.ReadFile("images/" + filename + ".pgm")
-	util.Check(ioError)
-
-	fields := strings.Fields(string(data))
-
-	if fields[0] != "P5" {
-		panic("Not a pgm file")
+	image, ioError := io.loadImage(filepath.Join(io.images, filename))
+	io.channels.errors <- ioError
+	if ioError != nil {
+		return
 	}
 
-	width, _ := strconv.Atoi(fields[1])
-	if width != io.params.ImageWidth {
-		panic("Incorrect width")
-	}
+	io.channels.input <- image
 
-	height, _ := strconv.Atoi(fields[2])
-	if height != io.params.ImageHeight {
-		panic("Incorrect height")
+	if !io.params.Quiet {
+		fmt.Println("File", filename, "input done!")
 	}
+}
 
-	maxval, _ := strconv.Atoi(fields[3])
-	if maxval != 255 {
-		panic("Incorrect maxval/bit depth")
+// loadImage reads the first of path.pgm, path.pgm.gz and path.golp that exists,
+// checking it matches the size of the board.
+func (io *ioState) loadImage(path string) ([]byte, error) {
+	var file *os.File
+	var ioError error
+	for _, format := range Formats {
+		file, ioError = os.Open(path + "." + format)
+		if !os.IsNotExist(ioError) {
+			path += "." + format
+			break
+		}
 	}
-
-	image := []byte(fields[4])
-
-	for _, b := range image {
-		io.channels.input <- b
+	if ioError != nil {
+		if os.IsNotExist(ioError) {
+			return nil, fmt.Errorf("%v: no image in any of the formats %v", path, Formats)
+		}
+		return nil, ioError
 	}
-
-	fmt.Println("File", filename, "input done!")
+	defer file.Close()
+	return decodeWorld(path, file, io.params.ImageWidth, io.params.ImageHeight)
 }
 
-// startIo should be the entrypoint of the io goroutine.
+// startIo should be the entrypoint of the io goroutine. It returns after an ioShutdown command.
 func startIo(p Params, c ioChannels) {
 	io := ioState{
 		params:   p,
 		channels: c,
+		images:   "images",
 	}
+	io.run()
+}
 
+// run answers the distributor's commands until an ioShutdown command.
+func (io *ioState) run() {
 	for {
 		select {
 		// Block and wait for requests from the distributor
 		case command := <-io.channels.command:
 			switch command {
 			case ioInput:
-				io.readPgmImage()
+				io.readImage()
 			case ioOutput:
-				io.writePgmImage()
+				io.writeImage()
 			case ioCheckIdle:
-				io.channels.idle <- true
+				io.channels.idle <- io.outputError
+			case ioShutdown:
+				return
 			}
 		}
 	}
@@ -0,0 +1,81 @@
+package gol
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// images is the directory of images at the root of the repository, where a game reads them from.
+const images = "../images"
+
+// BenchmarkLoad times reading every image in images/ into the world, through the io goroutine and
+// loadWorld as a game does, and the same with the image sent a byte per message as it used to be.
+func BenchmarkLoad(b *testing.B) {
+	files, err := ioutil.ReadDir(images)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, file := range files {
+		p := Params{Quiet: true} //only the benchmark results should be printed
+		if _, err := fmt.Sscanf(strings.TrimSuffix(file.Name(), ".pgm"), "%dx%d", &p.ImageWidth, &p.ImageHeight); err != nil {
+			continue
+		}
+		p.Threads = 8
+		loads := []struct {
+			name string
+			load func(b *testing.B, p Params)
+		}{{"message", loadMessage}, {"per-byte", loadPerByte}}
+		for _, l := range loads {
+			load := l.load
+			b.Run(file.Name()+"/"+l.name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(p.ImageWidth * p.ImageHeight))
+				for i := 0; i < b.N; i++ {
+					load(b, p)
+				}
+			})
+		}
+	}
+}
+
+// loadMessage loads the world the way distributor does.
+func loadMessage(b *testing.B, p Params) {
+	command := make(chan ioCommand)
+	filename := make(chan string)
+	input := make(chan []byte)
+	errors := make(chan error)
+	io := ioState{params: p, channels: ioChannels{command: command, errors: errors, filename: filename, input: input}, images: images}
+	go io.run()
+	c := distributorChannels{ioCommand: command, ioError: errors, ioFilename: filename, ioInput: input}
+	if err := loadWorld(p, c, createSlice(p, p.ImageHeight)); err != nil {
+		b.Fatal(err)
+	}
+	command <- ioShutdown
+}
+
+// loadPerByte reads the image the same way, but sends it to the world one byte at a time.
+func loadPerByte(b *testing.B, p Params) {
+	bytes := make(chan uint8)
+	go func() {
+		io := ioState{params: p}
+		image, err := io.loadImage(fmt.Sprintf("%v/%vx%v", images, p.ImageWidth, p.ImageHeight))
+		if err != nil {
+			b.Error(err)
+			close(bytes)
+			return
+		}
+		for _, val := range image {
+			bytes <- val
+		}
+	}()
+	world := createSlice(p, p.ImageHeight)
+	for y := range world {
+		for x := range world[y] {
+			if <-bytes != 0 {
+				world[y][x] = 0xFF
+			}
+		}
+	}
+}
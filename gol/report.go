@@ -0,0 +1,58 @@
+package gol
+
+import "time"
+
+// DefaultReportInterval is how often AliveCellsCount is sent when Params.ReportInterval is zero.
+const DefaultReportInterval = 2 * time.Second
+
+// aliveReporter sends the AliveCellsCount events: on a timer, every few turns and on request.
+// It remembers the last report so it can work out the throughput since then.
+type aliveReporter struct {
+	ticker   *time.Ticker
+	every    int
+	lastTurn int
+	lastTime time.Time
+}
+
+func newAliveReporter(p Params) *aliveReporter {
+	r := &aliveReporter{every: p.ReportTurns, lastTime: time.Now()}
+	interval := p.ReportInterval
+	if interval == 0 {
+		interval = DefaultReportInterval
+	}
+	if interval > 0 {
+		r.ticker = time.NewTicker(interval)
+	}
+	return r
+}
+
+// tick returns the timer channel. It is nil if timed reports are turned off, so it never fires in a select.
+func (r *aliveReporter) tick() <-chan time.Time {
+	if r.ticker == nil {
+		return nil
+	}
+	return r.ticker.C
+}
+
+// due reports whether a turn based report should be sent for the turn that just completed.
+func (r *aliveReporter) due(turn int) bool {
+	return r.every > 0 && turn%r.every == 0
+}
+
+// send sends an AliveCellsCount event including the turns per second since the previous report.
+func (r *aliveReporter) send(c distributorChannels, turn, alive int) {
+	now := time.Now()
+	tps := 0.0
+	if elapsed := now.Sub(r.lastTime).Seconds(); elapsed > 0 {
+		tps = float64(turn-r.lastTurn) / elapsed
+	}
+	c.events <- AliveCellsCount{turn, alive, tps}
+	r.lastTurn = turn
+	r.lastTime = now
+}
+
+func (r *aliveReporter) stop() {
+	if r.ticker != nil {
+		r.ticker.Stop()
+	}
+}
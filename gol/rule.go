@@ -0,0 +1,189 @@
+package gol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultRule is Conway's Game of Life, used when Params.Rule is empty.
+const DefaultRule = "B3/S23"
+
+// Rule is a parsed isotropic non-totalistic (Hensel notation) rule such as B3/S23 or B2-a/S12.
+// table is indexed by the 3x3 neighbourhood of a cell, one bit per cell:
+//
+//	 1   2   4
+//	 8  16  32
+//	64 128 256
+//
+// with the centre cell itself at bit 16. An entry is true if the cell is alive in the next turn.
+type Rule struct {
+	name  string
+	table [512]bool
+}
+
+// henselLetters lists the letters allowed after each neighbour count. Counts 5-7 reuse the
+// letters of 3-1 and describe the complement of that neighbourhood.
+var henselLetters = [9]string{"", "ce", "ceaikn", "ceaiknjqry", "ceaiknjqrtwyz", "ceaiknjqry", "ceaikn", "ce", ""}
+
+// henselNeighbourhoods holds one representative neighbourhood (centre excluded) for each letter
+// in henselLetters[1..4]. Every other neighbourhood is a rotation or reflection of one of these.
+var henselNeighbourhoods = [5][]int{
+	{0},
+	{1, 2},
+	{5, 10, 3, 40, 33, 68},
+	{69, 42, 11, 7, 98, 13, 14, 70, 41, 97},
+	{325, 170, 15, 45, 99, 71, 106, 102, 43, 101, 105, 78, 108},
+}
+
+// neighbourMask has a bit set for each of the 8 neighbours, i.e. everything but the centre.
+const neighbourMask = 511 &^ 16
+
+// ParseRule parses a rule string in B/S notation. Totalistic rules (B3/S23) and
+// Hensel notation (B2-a/S12, B3/S2ae3) are both accepted. An empty string gives DefaultRule.
+func ParseRule(s string) (Rule, error) {
+	if s == "" {
+		s = DefaultRule
+	}
+	var rule Rule
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return rule, fmt.Errorf("rule %q: expected the form B.../S...", s)
+	}
+	seen := map[int]bool{}
+	for _, part := range parts {
+		if part == "" {
+			return rule, fmt.Errorf("rule %q: empty B or S section", s)
+		}
+		var centre int
+		switch part[0] {
+		case 'B', 'b':
+			centre = 0
+		case 'S', 's':
+			centre = 16
+		default:
+			return rule, fmt.Errorf("rule %q: section %q should start with B or S", s, part)
+		}
+		if seen[centre] {
+			return rule, fmt.Errorf("rule %q: expected one B and one S section", s)
+		}
+		seen[centre] = true
+		err := rule.addTransitions(part[1:], centre)
+		if err != nil {
+			return rule, fmt.Errorf("rule %q: %v", s, err)
+		}
+	}
+	rule.name = s
+	return rule, nil
+}
+
+// addTransitions parses the body of a B or S section (everything after the letter) and
+// marks the matching neighbourhoods as alive for the given centre bit.
+func (rule *Rule) addTransitions(body string, centre int) error {
+	for i := 0; i < len(body); {
+		c := body[i]
+		if c < '0' || c > '8' {
+			return fmt.Errorf("unexpected character %q", c)
+		}
+		count := int(c - '0')
+		i++
+
+		negate := false
+		if i < len(body) && body[i] == '-' {
+			negate = true
+			i++
+		}
+		start := i
+		for i < len(body) && body[i] >= 'a' && body[i] <= 'z' {
+			i++
+		}
+		letters := body[start:i]
+		if negate && letters == "" {
+			return fmt.Errorf("'-' after %d must be followed by letters", count)
+		}
+
+		for _, l := range letters {
+			if !strings.ContainsRune(henselLetters[count], l) {
+				return fmt.Errorf("letter %q is not valid for %d neighbours", l, count)
+			}
+		}
+		for index := 0; index < 512; index++ {
+			if index&16 != centre || bitCount(index&neighbourMask) != count {
+				continue
+			}
+			//no letters means every neighbourhood with this count
+			if letters == "" {
+				rule.table[index] = true
+				continue
+			}
+			matched := strings.ContainsRune(letters, henselLetter(index&neighbourMask, count))
+			if matched != negate {
+				rule.table[index] = true
+			}
+		}
+	}
+	return nil
+}
+
+// String returns the rule as it was given to ParseRule.
+func (rule Rule) String() string {
+	return rule.name
+}
+
+// next looks up the next state of a cell from its 3x3 neighbourhood index.
+func (rule *Rule) next(index int) bool {
+	return rule.table[index]
+}
+
+// henselLetter finds the letter naming a neighbourhood (centre excluded) with count live neighbours.
+func henselLetter(neighbours, count int) rune {
+	if count == 0 || count == 8 {
+		return 0
+	}
+	if count > 4 {
+		neighbours = ^neighbours & neighbourMask
+		count = 8 - count
+	}
+	for _, symmetric := range symmetries(neighbours) {
+		for i, representative := range henselNeighbourhoods[count] {
+			if symmetric == representative {
+				return rune(henselLetters[count][i])
+			}
+		}
+	}
+	panic(fmt.Sprintf("no Hensel letter for neighbourhood %d", neighbours))
+}
+
+// symmetries returns the 8 rotations and reflections of a 3x3 neighbourhood.
+func symmetries(index int) [8]int {
+	var result [8]int
+	current := index
+	for i := 0; i < 4; i++ {
+		result[2*i] = current
+		result[2*i+1] = transform(current, func(x, y int) (int, int) { return 2 - x, y })
+		current = transform(current, func(x, y int) (int, int) { return 2 - y, x })
+	}
+	return result
+}
+
+// transform moves every bit of a neighbourhood index to the position given by f.
+func transform(index int, f func(x, y int) (int, int)) int {
+	result := 0
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 3; x++ {
+			if index&(1<<uint(y*3+x)) != 0 {
+				nx, ny := f(x, y)
+				result |= 1 << uint(ny*3+nx)
+			}
+		}
+	}
+	return result
+}
+
+func bitCount(n int) int {
+	count := 0
+	for n != 0 {
+		count += n & 1
+		n >>= 1
+	}
+	return count
+}
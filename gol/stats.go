@@ -0,0 +1,86 @@
+package gol
+
+import "uk.ac.bris.cs/gameoflife/util"
+
+// activityTiles is how many regions across and down the TurnStats activity heatmap has.
+const activityTiles = 16
+
+// workerStats is filled in by each worker for its band of rows during a turn.
+// Every worker has its own, so they can be written without locking.
+type workerStats struct {
+	alive, births, deaths  int
+	minX, minY, maxX, maxY int         // bounding box of the live cells in the band
+	flipped                []util.Cell // cells that changed state this turn
+	hash                   uint64      // FNV-1a hash of the band after this turn, see hashBands
+}
+
+// reset clears the stats before a new turn, keeping the flipped slice's memory.
+func (s *workerStats) reset() {
+	*s = workerStats{minX: -1, minY: -1, maxX: -1, maxY: -1, flipped: s.flipped[:0], hash: fnvOffset}
+}
+
+// addAlive records a cell that is alive after this turn.
+func (s *workerStats) addAlive(x, y int) {
+	s.alive++
+	if s.minY == -1 {
+		s.minX, s.minY, s.maxX, s.maxY = x, y, x, y
+		return
+	}
+	if x < s.minX {
+		s.minX = x
+	}
+	if x > s.maxX {
+		s.maxX = x
+	}
+	if y > s.maxY {
+		s.maxY = y
+	}
+}
+
+// turnStats combines the workers' stats into a TurnStats event.
+func turnStats(p Params, turn int, stats []workerStats) TurnStats {
+	event := TurnStats{
+		CompletedTurns: turn,
+		Bands:          make([]int, len(stats)),
+		Activity:       make([][]int, activityTiles),
+	}
+	for i := range event.Activity {
+		event.Activity[i] = make([]int, activityTiles)
+	}
+	tileWidth := (p.ImageWidth + activityTiles - 1) / activityTiles
+	tileHeight := (p.ImageHeight + activityTiles - 1) / activityTiles
+
+	found := false
+	for i, s := range stats {
+		event.Alive += s.alive
+		event.Births += s.births
+		event.Deaths += s.deaths
+		event.Bands[i] = s.alive
+		for _, cell := range s.flipped {
+			event.Activity[cell.Y/tileHeight][cell.X/tileWidth]++
+		}
+		if s.alive == 0 {
+			continue
+		}
+		if !found {
+			event.Min = util.Cell{X: s.minX, Y: s.minY}
+			event.Max = util.Cell{X: s.maxX, Y: s.maxY}
+			found = true
+			continue
+		}
+		if s.minX < event.Min.X {
+			event.Min.X = s.minX
+		}
+		if s.maxX > event.Max.X {
+			event.Max.X = s.maxX
+		}
+		if s.minY < event.Min.Y {
+			event.Min.Y = s.minY
+		}
+		if s.maxY > event.Max.Y {
+			event.Max.Y = s.maxY
+		}
+	}
+	event.Density = float64(event.Alive) / float64(p.ImageWidth*p.ImageHeight)
+	return event
+}
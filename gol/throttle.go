@@ -0,0 +1,70 @@
+package gol
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxTargetTPS is the fastest target turn rate; speeding up past it removes the limit.
+const maxTargetTPS = 1 << 20
+
+// throttle holds the distributor back to a target number of turns per second.
+// A target of 0 means no limit, so turns run as fast as they can.
+type throttle struct {
+	tps      float64
+	last     time.Time     //when the last turn started
+	duration time.Duration //how long the last turn took between starts, to pick a rate when slowing down
+}
+
+func newThrottle(tps float64) *throttle {
+	return &throttle{tps: tps}
+}
+
+// untilNextTurn returns how long to wait before starting the next turn, 0 or less if it can start now.
+func (t *throttle) untilNextTurn() time.Duration {
+	if t.tps <= 0 || t.last.IsZero() {
+		return 0
+	}
+	return time.Until(t.last.Add(time.Duration(float64(time.Second) / t.tps)))
+}
+
+// started records the start of a turn.
+func (t *throttle) started() {
+	now := time.Now()
+	if !t.last.IsZero() {
+		t.duration = now.Sub(t.last)
+	}
+	t.last = now
+}
+
+// faster doubles the target rate, removing the limit once it gets past maxTargetTPS.
+func (t *throttle) faster() {
+	if t.tps <= 0 {
+		return
+	}
+	t.tps *= 2
+	if t.tps > maxTargetTPS {
+		t.tps = 0
+	}
+}
+
+// slower halves the target rate. Without a limit it starts from half the current rate.
+func (t *throttle) slower() {
+	if t.tps > 0 {
+		t.tps /= 2
+	} else if t.duration > 0 {
+		t.tps = float64(time.Second) / float64(t.duration) / 2
+	} else {
+		t.tps = maxTargetTPS / 2
+	}
+	if t.tps < 1.0/64 {
+		t.tps = 1.0 / 64
+	}
+}
+
+func (t *throttle) String() string {
+	if t.tps <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%.2f turns/s", t.tps)
+}
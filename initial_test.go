@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestInitialWorld checks that a game can start from a world, a list of cells or a pgm reader
+// given in Params instead of a file in images/, giving the same result as the file would.
+func TestInitialWorld(t *testing.T) {
+	p := gol.Params{Turns: 100, Threads: 4, ImageWidth: 64, ImageHeight: 64}
+	cells := readAliveCells("images/64x64.pgm", p.ImageWidth, p.ImageHeight)
+	expected := readAliveCells("check/images/64x64x100.pgm", p.ImageWidth, p.ImageHeight)
+
+	world := make([][]byte, p.ImageHeight)
+	for y := range world {
+		world[y] = make([]byte, p.ImageWidth)
+	}
+	for _, cell := range cells {
+		world[cell.Y][cell.X] = 0xFF
+	}
+	image, err := os.Open("images/64x64.pgm")
+	util.Check(err)
+	defer image.Close()
+
+	tests := map[string]gol.Params{
+		"world": withInitial(p, func(p *gol.Params) { p.InitialWorld = world }),
+		"cells": withInitial(p, func(p *gol.Params) { p.InitialCells = cells }),
+		"image": withInitial(p, func(p *gol.Params) { p.InitialImage = image }),
+	}
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			alive, err := runGame(params, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEqualBoard(t, alive, expected, params)
+		})
+	}
+
+	//a cell outside the board can't be placed
+	bad := withInitial(p, func(p *gol.Params) { p.InitialCells = []util.Cell{{X: 64, Y: 0}} })
+	if _, err := runGame(bad, nil); err == nil {
+		t.Error("expected an error for an initial cell outside the world")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestNoGoroutineLeak checks that finishing, quitting, cancelling and failing to start a game
+// all leave no goroutines behind, so repeated runs don't pile them up.
+func TestNoGoroutineLeak(t *testing.T) {
+	p := gol.Params{Turns: 10, Threads: 8, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1}
+	drain := func(events <-chan gol.Event) {
+		for range events {
+		}
+	}
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 20; i++ {
+		events := make(chan gol.Event)
+		go gol.Run(p, events, nil)
+		drain(events)
+
+		keyPresses := make(chan rune, 1)
+		keyPresses <- 'q'
+		events = make(chan gol.Event)
+		go gol.Run(gol.Params{Turns: 100000000, Threads: 8, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1}, events, keyPresses)
+		drain(events)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		events = make(chan gol.Event)
+		go gol.RunContext(ctx, gol.Params{Turns: 100000000, Threads: 8, ImageWidth: 16, ImageHeight: 16}, events, nil)
+		drain(events)
+
+		events = make(chan gol.Event)
+		go gol.Run(gol.Params{Turns: 10, Threads: 8, ImageWidth: 17, ImageHeight: 17}, events, nil)
+		drain(events)
+	}
+
+	//goroutines that have been told to stop may take a moment to go
+	after := runtime.NumGoroutine()
+	for deadline := time.Now().Add(2 * time.Second); after > before && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		after = runtime.NumGoroutine()
+	}
+	if after > before {
+		buf := make([]byte, 1<<16)
+		t.Errorf("expected at most %v goroutines after 80 runs, got %v:\n%s", before, after, buf[:runtime.Stack(buf, true)])
+	}
+}
@@ -3,66 +3,246 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
 	"runtime"
+	"strings"
 
 	"uk.ac.bris.cs/gameoflife/gol"
-	"uk.ac.bris.cs/gameoflife/sdl"
+	"uk.ac.bris.cs/gameoflife/recording"
+	"uk.ac.bris.cs/gameoflife/term"
+	"uk.ac.bris.cs/gameoflife/visual"
 )
 
+// command is one of the things the program can do, chosen by the first argument, e.g. 'go run . info image.pgm'.
+type command struct {
+	name    string
+	args    string // what goes after the flags, for the help text
+	summary string
+	run     func(args []string)
+}
+
+// commands is filled in by init, as the help command needs to list them all.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"run", "", "Run the Game of Life (the default when no command is given).", run},
+		{"replay", "<recording>", "Play back a recording made with 'run -record'.", replay},
+		{"bench", "", "Time a game headless with different numbers of worker threads.", bench},
+		{"convert", "<from> <to>", "Convert a board between " + strings.Join(boardFormats(), ", ") + ", chosen by file extension.", convert},
+		{"info", "<file>...", "Print the size, population and saved details of boards.", info},
+		{"diff", "<a> <b>", "Compare two boards cell by cell.", diff},
+		{"help", "", "Show this help.", help},
+	}
+}
+
 // main is the function called when starting Game of Life with 'go run .'
 func main() {
 	runtime.LockOSThread()
+	args := os.Args[1:]
+	//just flags (or nothing) runs the game, as it did before there were commands
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		run(args)
+		return
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			c.run(args[1:])
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Unknown command %q.\n\n", args[0])
+	help(nil)
+	os.Exit(2)
+}
+
+// help lists the commands.
+func help(args []string) {
+	fmt.Fprintln(os.Stderr, "Usage: go run . [command] [flags] [arguments]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8v %v\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nUse 'go run . <command> -help' for the flags of a command.")
+}
+
+// newFlags makes the flag set for a command, with help text listing its arguments and flags.
+func newFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		for _, c := range commands {
+			if c.name == name {
+				fmt.Fprintf(flags.Output(), "Usage: go run . %v [flags] %v\n%v\n\nFlags:\n", name, c.args, c.summary)
+			}
+		}
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// run runs the Game of Life on images/<width>x<height>.pgm with a viewer.
+func run(args []string) {
+	flags := newFlags("run")
 	var params gol.Params
 
-	flag.IntVar(
+	flags.IntVar(
 		&params.Threads,
 		"t",
 		8,
 		"Specify the number of worker threads to use. Defaults to 8.")
 
-	flag.IntVar(
+	flags.IntVar(
 		&params.ImageWidth,
 		"w",
 		512,
 		"Specify the width of the image. Defaults to 512.")
 
-	flag.IntVar(
+	flags.IntVar(
 		&params.ImageHeight,
 		"h",
 		512,
 		"Specify the height of the image. Defaults to 512.")
 
-	flag.IntVar(
+	flags.IntVar(
 		&params.Turns,
 		"turns",
 		10000000000,
 		"Specify the number of turns to process. Defaults to 10000000000.")
 
-	noVis := flag.Bool(
+	flags.StringVar(
+		&params.Rule,
+		"rule",
+		gol.DefaultRule,
+		"Specify the rule in B/S notation, e.g. B3/S23 or the Hensel notation B2-a/S12. Defaults to B3/S23.")
+
+	flags.StringVar(
+		&params.Format,
+		"format",
+		gol.FormatPGM,
+		"Specify the format images are saved in: "+strings.Join(gol.Formats, ", ")+". Images in any of them can be read from images/.")
+
+	flags.BoolVar(
+		&params.FastForward,
+		"fastForward",
+		false,
+		"Skip straight to the final turn once the world is found to repeat.")
+
+	flags.BoolVar(
+		&params.Census,
+		"census",
+		false,
+		"Count the known objects (blocks, blinkers, gliders...) on the final board. Press c to count at any time.")
+
+	statsFile := flags.String(
+		"stats",
+		"",
+		"Write the alive cells, births, deaths, density and bounding box after every turn to the given CSV file.")
+
+	flags.DurationVar(
+		&params.ReportInterval,
+		"reportInterval",
+		gol.DefaultReportInterval,
+		"Specify how often to report the number of alive cells, e.g. 500ms. A negative interval turns timed reports off.")
+
+	flags.IntVar(
+		&params.ReportTurns,
+		"reportTurns",
+		0,
+		"Also report the number of alive cells every this many turns. Press a to report at any time.")
+
+	addSDLFlags(flags)
+
+	flags.Float64Var(
+		&params.TargetTPS,
+		"tps",
+		0,
+		"Specify the number of turns per second to run at. Defaults to 0, as fast as possible. Press + or - to change it.")
+
+	flags.IntVar(
+		&params.History,
+		"history",
+		gol.DefaultHistory,
+		"Specify how many past turns are kept, forgetting the oldest on a busy board to stay under 16MB. While paused, b steps back through them and f forwards again.")
+
+	fps := flags.Float64(
+		"fps",
+		60,
+		"Specify the most frames per second the viewer draws, skipping turns in between. 0 draws every turn.")
+
+	termVis := flags.Bool(
+		"term",
+		false,
+		"Draws the board in the terminal instead of an SDL window.")
+
+	var termOptions term.Options
+	flags.BoolVar(
+		&termOptions.Braille,
+		"braille",
+		false,
+		"Draws the board in the terminal with braille characters, fitting 2x4 cells in each one.")
+
+	recordFile := flags.String(
+		"record",
+		"",
+		"Record every turn to the given file, to watch again with 'go run . replay <file>'.")
+
+	logEvents := flags.Bool(
+		"log",
+		false,
+		"Print every event to stdout, e.g. alongside -noVis.")
+
+	noVis := flags.Bool(
 		"noVis",
 		false,
-		"Disables the SDL window, so there is no visualisation during the tests.")
+		"Disables the viewer, so there is no visualisation during the tests.")
 
-	flag.Parse()
+	_ = flags.Parse(args)
+
+	if _, err := gol.ParseRule(params.Rule); err != nil {
+		fmt.Println("Invalid rule:", err)
+		os.Exit(1)
+	}
 
 	fmt.Println("Threads:", params.Threads)
 	fmt.Println("Width:", params.ImageWidth)
 	fmt.Println("Height:", params.ImageHeight)
+	fmt.Println("Rule:", params.Rule)
+
+	if *statsFile != "" {
+		params.Stats = true
+	}
 
 	keyPresses := make(chan rune, 10)
-	events := make(chan gol.Event, 1000)
-
-	go gol.Run(params, events, keyPresses)
-	if !(*noVis) {
-		sdl.Run(params, events, keyPresses)
-	} else {
-		complete := false
-		for !complete {
-			event := <-events
-			switch event.(type) {
-			case gol.FinalTurnComplete:
-				complete = true
-			}
-		}
+	edits := make(chan gol.CellEdit, 1000)
+	golEvents := make(chan gol.Event, 1000)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- gol.RunWithEdits(params, golEvents, keyPresses, edits)
+	}()
+
+	//the viewer goes first so SDL stays on the main thread
+	var viewer visual.Visualiser = visual.Headless{}
+	if *termVis {
+		termOptions.FPS = *fps
+		viewer = term.Viewer{Options: termOptions}
+	} else if !(*noVis) {
+		viewer = sdlViewer(*fps)
+	}
+	visualisers := visual.FanOut{viewer}
+	if *logEvents {
+		visualisers = append(visualisers, visual.Log{Out: os.Stdout})
+	}
+	if *statsFile != "" {
+		visualisers = append(visualisers, statsWriter(*statsFile))
+	}
+	if *recordFile != "" {
+		visualisers = append(visualisers, recording.Recorder{Filename: *recordFile})
+	}
+	visualisers.Visualise(params, golEvents, keyPresses, edits)
+
+	if err := <-runErr; err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
 	}
 }
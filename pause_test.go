@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestPause checks that a paused board can be stepped one turn at a time with 'n', saved with 's'
+// and quit with 'q', and that the quit still reports the final state and closes the events channel.
+func TestPause(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1}
+	alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+	keyPresses := make(chan rune, 10)
+	for _, k := range "pnnnsq" {
+		keyPresses <- k
+	}
+
+	var states []gol.State
+	turns := 0
+	saves := 0
+	final := false
+	_, err := runGameWithKeys(p, keyPresses, func(event gol.Event) {
+		switch e := event.(type) {
+		case gol.StateChange:
+			states = append(states, e.NewState)
+		case gol.TurnComplete:
+			turns++
+			if len(states) != 1 || states[0] != gol.Paused {
+				t.Errorf("turn %v completed while not paused, states so far %v", e.CompletedTurns, states)
+			}
+		case gol.ImageOutputComplete:
+			saves++
+			if e.CompletedTurns != 3 {
+				t.Errorf("expected the image to be saved at turn 3, got turn %v", e.CompletedTurns)
+			}
+		case gol.FinalTurnComplete:
+			final = true
+			if e.CompletedTurns != 3 || len(e.Alive) != alive[3] {
+				t.Errorf("expected %v alive cells at turn 3, got %v at turn %v", alive[3], len(e.Alive), e.CompletedTurns)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if turns != 3 {
+		t.Errorf("expected 3 steps, got %v", turns)
+	}
+	if saves != 2 {
+		t.Errorf("expected 2 images saved ('s' and 'q'), got %v", saves)
+	}
+	if !final {
+		t.Error("no FinalTurnComplete event received after quitting")
+	}
+	if len(states) != 2 || states[1] != gol.Quitting {
+		t.Errorf("expected states [Paused Quitting], got %v", states)
+	}
+}
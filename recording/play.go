@@ -0,0 +1,154 @@
+package recording
+
+import (
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// Options control how a recording is played back.
+type Options struct {
+	TPS  float64 // turns per second to play at, 0 means as fast as possible
+	Seek int     // the turn to start from
+}
+
+// Play sends the events of a recorded game, as if gol.Run was running it, without computing any turns.
+// It starts from the first frame at or after opts.Seek and understands the same keys as gol.Run:
+// 'p' pauses, 'q' quits, '+' and '-' change the speed, and while paused 'n' or 'f' steps forwards
+// a frame and 'b' steps back one. Once the recording ends (or 'q' is pressed) it sends
+// FinalTurnComplete and StateChange{Quitting}, then closes events.
+func Play(rec *Recording, events chan<- gol.Event, keyPresses <-chan rune, opts Options) {
+	world := make([][]bool, rec.Height)
+	for y := range world {
+		world[y] = make([]bool, rec.Width)
+	}
+	turn := 0
+	next := 0 //the frame to play next
+
+	//flips the cells of a frame, letting the viewer know unless it is being skipped over
+	apply := func(frame Frame, newTurn int, send bool) {
+		turn = newTurn
+		for _, cell := range frame.Flips {
+			world[cell.Y][cell.X] = !world[cell.Y][cell.X]
+			if send {
+				events <- gol.CellFlipped{CompletedTurns: turn, Cell: cell}
+			}
+		}
+		if send {
+			events <- gol.TurnComplete{CompletedTurns: turn}
+		}
+	}
+	forward := func(send bool) {
+		apply(rec.Frames[next], rec.Frames[next].Turn, send)
+		next++
+	}
+	back := func() {
+		if next <= 1 {
+			return
+		}
+		next--
+		apply(rec.Frames[next], rec.Frames[next-1].Turn, true)
+	}
+
+	//skip to the turn to start from, then show the world as it is there
+	for next < len(rec.Frames) && (next == 0 || turn < opts.Seek) {
+		forward(false)
+	}
+	for y := range world {
+		for x := range world[y] {
+			if world[y][x] {
+				events <- gol.CellFlipped{CompletedTurns: turn, Cell: util.Cell{X: x, Y: y}}
+			}
+		}
+	}
+	events <- gol.TurnComplete{CompletedTurns: turn}
+
+	tps := opts.TPS
+	var tick <-chan time.Time
+	var ticker *time.Ticker
+	setSpeed := func() {
+		if ticker != nil {
+			ticker.Stop()
+			ticker, tick = nil, nil
+		}
+		if tps > 0 {
+			ticker = time.NewTicker(time.Duration(float64(time.Second) / tps))
+			tick = ticker.C
+		}
+	}
+	setSpeed()
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+
+	state := gol.Executing
+	handleKey := func(k rune) {
+		switch k {
+		case 'p':
+			if state == gol.Paused {
+				state = gol.Executing
+			} else {
+				state = gol.Paused
+			}
+			events <- gol.StateChange{CompletedTurns: turn, NewState: state}
+		case 'q':
+			state = gol.Quitting
+		case '+':
+			if tps > 0 {
+				tps *= 2
+				setSpeed()
+			}
+		case '-':
+			if tps == 0 {
+				tps = 64
+			}
+			tps /= 2
+			setSpeed()
+		case 'n', 'f':
+			if state == gol.Paused && next < len(rec.Frames) {
+				forward(true)
+			}
+		case 'b':
+			if state == gol.Paused {
+				back()
+			}
+		}
+	}
+
+	for state != gol.Quitting && (next < len(rec.Frames) || state == gol.Paused) {
+		if state == gol.Paused {
+			handleKey(<-keyPresses)
+			continue
+		}
+		if tick == nil {
+			select {
+			case k := <-keyPresses:
+				handleKey(k)
+			default:
+				forward(true)
+			}
+			continue
+		}
+		select {
+		case k := <-keyPresses:
+			handleKey(k)
+		case <-tick:
+			forward(true)
+		}
+	}
+
+	var alive []util.Cell
+	for y := range world {
+		for x := range world[y] {
+			if world[y][x] {
+				alive = append(alive, util.Cell{X: x, Y: y})
+			}
+		}
+	}
+	events <- gol.FinalTurnComplete{CompletedTurns: turn, Alive: alive}
+	events <- gol.StateChange{CompletedTurns: turn, NewState: gol.Quitting}
+	close(events)
+}
@@ -0,0 +1,245 @@
+package recording
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// magic starts every recording, followed by version.
+const magic = "GOLR"
+const version = 1
+
+// maxRule is the longest rule Read accepts, so a corrupt header can't ask for a huge one.
+const maxRule = 256
+
+// Recording is a whole game: the starting world and the cells that flipped in every turn after it,
+// the same as the CellFlipped events sent while it ran.
+type Recording struct {
+	Width, Height int
+	Rule          string
+	Frames        []Frame
+}
+
+// Frame is the cells that flipped to reach a turn. The first frame is the starting world, all its
+// cells flipping from dead. Edits and rewinding make frames of their own, so turns can repeat or go back.
+type Frame struct {
+	Turn  int
+	Flips []util.Cell
+}
+
+// Recorder is a visual.Visualiser that writes the game to a recording file as it runs.
+// If the file can't be written it says so and the game carries on without being recorded.
+type Recorder struct {
+	Filename string
+}
+
+// Visualise records every CellFlipped event until the final turn.
+func (r Recorder) Visualise(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+	if err := r.record(p, events); err != nil {
+		fmt.Println("Recording", r.Filename, "failed:", err)
+		//the game carries on without being recorded
+		for range events {
+		}
+	}
+}
+
+func (r Recorder) record(p gol.Params, events <-chan gol.Event) error {
+	file, err := os.Create(r.Filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	rule := p.Rule
+	if rule == "" {
+		rule = gol.DefaultRule
+	}
+	w, err := newWriter(file, p.ImageWidth, p.ImageHeight, rule)
+	if err != nil {
+		return err
+	}
+
+	//flips wait here until the turn they belong to is over
+	frame := Frame{}
+	pending := false
+	for event := range events {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			if pending && e.CompletedTurns != frame.Turn {
+				if err := w.writeFrame(frame); err != nil {
+					return err
+				}
+				frame.Flips = frame.Flips[:0]
+			}
+			frame.Turn = e.CompletedTurns
+			frame.Flips = append(frame.Flips, e.Cell)
+			pending = true
+		case gol.TurnComplete:
+			if pending && e.CompletedTurns != frame.Turn {
+				if err := w.writeFrame(frame); err != nil {
+					return err
+				}
+				frame.Flips = frame.Flips[:0]
+			}
+			//a turn where nothing changed still gets a frame, so the recording keeps time
+			frame.Turn = e.CompletedTurns
+			if err := w.writeFrame(frame); err != nil {
+				return err
+			}
+			frame.Flips = frame.Flips[:0]
+			pending = false
+		case gol.FinalTurnComplete:
+			if pending {
+				if err := w.writeFrame(frame); err != nil {
+					return err
+				}
+			}
+			return w.close()
+		}
+	}
+	return w.close()
+}
+
+// writer encodes a recording: a header, then gzip compressed frames of
+// the turn, the number of flips and the gaps between the sorted cell indexes (y*width+x), all as varints.
+type writer struct {
+	compressed *gzip.Writer
+	buffer     *bufio.Writer
+	width      int
+	indexes    []int
+}
+
+func newWriter(file io.Writer, width, height int, rule string) (*writer, error) {
+	header := []byte(magic)
+	header = append(header, version)
+	header = appendUvarint(header, uint64(width))
+	header = appendUvarint(header, uint64(height))
+	header = appendUvarint(header, uint64(len(rule)))
+	header = append(header, rule...)
+	if _, err := file.Write(header); err != nil {
+		return nil, err
+	}
+	compressed := gzip.NewWriter(file)
+	return &writer{compressed: compressed, buffer: bufio.NewWriter(compressed), width: width}, nil
+}
+
+func (w *writer) writeFrame(frame Frame) error {
+	w.indexes = w.indexes[:0]
+	for _, cell := range frame.Flips {
+		w.indexes = append(w.indexes, cell.Y*w.width+cell.X)
+	}
+	sort.Ints(w.indexes)
+
+	var data []byte
+	data = appendUvarint(data, uint64(frame.Turn))
+	data = appendUvarint(data, uint64(len(w.indexes)))
+	previous := 0
+	for _, index := range w.indexes {
+		data = appendUvarint(data, uint64(index-previous))
+		previous = index
+	}
+	_, err := w.buffer.Write(data)
+	return err
+}
+
+func (w *writer) close() error {
+	if err := w.buffer.Flush(); err != nil {
+		return err
+	}
+	return w.compressed.Close()
+}
+
+func appendUvarint(data []byte, n uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(data, buf[:binary.PutUvarint(buf[:], n)]...)
+}
+
+// Load reads a whole recording into memory.
+func Load(filename string) (*Recording, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	rec, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	return rec, nil
+}
+
+// Read decodes a recording written by a Recorder.
+func Read(r io.Reader) (*Recording, error) {
+	reader := bufio.NewReader(r)
+	start := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(reader, start); err != nil || string(start[:len(magic)]) != magic {
+		return nil, errors.New("not a recording")
+	}
+	if start[len(magic)] != version {
+		return nil, fmt.Errorf("unsupported recording version %v", start[len(magic)])
+	}
+	var header [3]uint64
+	for i := range header {
+		n, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return nil, fmt.Errorf("recording header: %v", err)
+		}
+		header[i] = n
+	}
+	if header[0] == 0 || header[1] == 0 || header[0] > gol.MaxImageCells || header[1] > gol.MaxImageCells/header[0] {
+		return nil, fmt.Errorf("recording header: bad size %vx%v", header[0], header[1])
+	}
+	if header[2] > maxRule {
+		return nil, fmt.Errorf("recording header: %v byte rule, at most %v are allowed", header[2], maxRule)
+	}
+	rule := make([]byte, header[2])
+	if _, err := io.ReadFull(reader, rule); err != nil {
+		return nil, fmt.Errorf("recording header: %v", err)
+	}
+	rec := &Recording{Width: int(header[0]), Height: int(header[1]), Rule: string(rule)}
+	cells := uint64(rec.Width * rec.Height)
+
+	compressed, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	frames := bufio.NewReader(compressed)
+	for {
+		turn, err := binary.ReadUvarint(frames)
+		if err == io.EOF {
+			return rec, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		count, err := binary.ReadUvarint(frames)
+		if err != nil {
+			return nil, fmt.Errorf("frame for turn %v: %v", turn, err)
+		}
+		if count > cells {
+			return nil, fmt.Errorf("frame for turn %v: %v flips is more than the cells in the world", turn, count)
+		}
+		frame := Frame{Turn: int(turn)}
+		index := uint64(0)
+		for i := uint64(0); i < count; i++ {
+			gap, err := binary.ReadUvarint(frames)
+			if err != nil {
+				return nil, fmt.Errorf("frame for turn %v: %v", turn, err)
+			}
+			if gap >= cells || index+gap >= cells {
+				return nil, fmt.Errorf("frame for turn %v: cell %v is outside the world", turn, index+gap)
+			}
+			index += gap
+			frame.Flips = append(frame.Flips, util.Cell{X: int(index) % rec.Width, Y: int(index) / rec.Width})
+		}
+		rec.Frames = append(rec.Frames, frame)
+	}
+}
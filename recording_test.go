@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/recording"
+	"uk.ac.bris.cs/gameoflife/util"
+	"uk.ac.bris.cs/gameoflife/visual"
+)
+
+// TestRecording records 100 turns of the 16x16 image, then checks that playing it back gives the
+// same final board and alive counts, and that seeking and stepping back land on the right turns.
+func TestRecording(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gol-recording")
+	util.Check(err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "16x16.golr")
+
+	p := gol.Params{Turns: 100, Threads: 4, ImageWidth: 16, ImageHeight: 16}
+	events := make(chan gol.Event)
+	result := make(chan error, 1)
+	go func() {
+		result <- gol.Run(p, events, nil)
+	}()
+	visual.FanOut{recording.Recorder{Filename: filename}}.Visualise(p, events, nil, nil)
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := recording.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Width != 16 || rec.Height != 16 || rec.Rule != gol.DefaultRule {
+		t.Errorf("expected a 16x16 %v recording, got %vx%v %v", gol.DefaultRule, rec.Width, rec.Height, rec.Rule)
+	}
+	alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+	expected := readAliveCells("check/images/16x16x100.pgm", p.ImageWidth, p.ImageHeight)
+
+	tests := []struct {
+		name  string
+		keys  string
+		seek  int
+		final int
+	}{
+		{"whole", "", 0, 100},
+		{"seek", "pq", 50, 50},
+		{"back", "pbbq", 50, 48},
+		{"step", "pbnfq", 50, 51},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keyPresses := make(chan rune, 10)
+			for _, k := range test.keys {
+				keyPresses <- k
+			}
+			events := make(chan gol.Event)
+			go recording.Play(rec, events, keyPresses, recording.Options{Seek: test.seek})
+
+			board := make(map[util.Cell]bool)
+			for event := range events {
+				switch e := event.(type) {
+				case gol.CellFlipped:
+					if board[e.Cell] {
+						delete(board, e.Cell)
+					} else {
+						board[e.Cell] = true
+					}
+				case gol.TurnComplete:
+					if e.CompletedTurns > 0 && len(board) != alive[e.CompletedTurns] {
+						t.Errorf("expected %v alive cells at turn %v, got %v", alive[e.CompletedTurns], e.CompletedTurns, len(board))
+					}
+				case gol.FinalTurnComplete:
+					if e.CompletedTurns != test.final {
+						t.Errorf("expected to finish at turn %v, got %v", test.final, e.CompletedTurns)
+					}
+					if test.final == 100 {
+						assertEqualBoard(t, e.Alive, expected, p)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestCorruptRecording checks that recordings with impossible headers or frames give an error
+// instead of making room for them.
+func TestCorruptRecording(t *testing.T) {
+	header := func(values ...uint64) []byte {
+		data := []byte("GOLR\x01")
+		for _, v := range values {
+			var buf [binary.MaxVarintLen64]byte
+			data = append(data, buf[:binary.PutUvarint(buf[:], v)]...)
+		}
+		return data
+	}
+	frames := func(data []byte, values ...uint64) []byte {
+		var b bytes.Buffer
+		b.Write(data)
+		compressed := gzip.NewWriter(&b)
+		for _, v := range values {
+			var buf [binary.MaxVarintLen64]byte
+			_, _ = compressed.Write(buf[:binary.PutUvarint(buf[:], v)])
+		}
+		util.Check(compressed.Close())
+		return b.Bytes()
+	}
+	tests := map[string][]byte{
+		"huge size":     header(1<<40, 1<<40, 0),
+		"overflow":      header(1<<62, 4, 0),
+		"long rule":     header(3, 3, 1<<40),
+		"truncated":     header(3),
+		"flip outside":  frames(header(3, 3, 0), 0, 1, 1<<63),
+		"too many":      frames(header(3, 3, 0), 0, 1<<40),
+		"not recording": []byte("GOLP\x01"),
+	}
+	for name, data := range tests {
+		if _, err := recording.Read(bytes.NewReader(data)); err == nil {
+			t.Errorf("%v: expected an error", name)
+		}
+	}
+	if _, err := recording.Read(bytes.NewReader(frames(header(3, 3, 0), 0, 1, 8))); err != nil {
+		t.Errorf("expected a flip in the last cell to be read, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/recording"
+	"uk.ac.bris.cs/gameoflife/term"
+	"uk.ac.bris.cs/gameoflife/visual"
+)
+
+// replay plays back a recording made with -record, without computing any turns.
+// Press p to pause, then b and f to step back and forwards.
+func replay(args []string) {
+	flags := newFlags("replay")
+	var opts recording.Options
+	flags.Float64Var(&opts.TPS, "tps", 30, "Specify the number of turns per second to play at. 0 plays as fast as possible. Press + or - to change it.")
+	flags.IntVar(&opts.Seek, "seek", 0, "Start from this turn.")
+	fps := flags.Float64("fps", 60, "Specify the most frames per second the viewer draws, skipping turns in between. 0 draws every turn.")
+	termVis := flags.Bool("term", false, "Draws the board in the terminal instead of an SDL window.")
+	noVis := flags.Bool("noVis", false, "Disables the viewer, printing the final turn only.")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rec, err := recording.Load(flags.Arg(0))
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	p := gol.Params{ImageWidth: rec.Width, ImageHeight: rec.Height, Rule: rec.Rule, TargetTPS: opts.TPS}
+	if len(rec.Frames) > 0 {
+		p.Turns = rec.Frames[len(rec.Frames)-1].Turn
+	}
+	fmt.Println("Width:", p.ImageWidth)
+	fmt.Println("Height:", p.ImageHeight)
+	fmt.Println("Rule:", p.Rule)
+	fmt.Println("Turns:", p.Turns)
+
+	var viewer visual.Visualiser = visual.Func(func(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+		for event := range events {
+			if final, ok := event.(gol.FinalTurnComplete); ok {
+				fmt.Printf("Completed Turns %-8vAlive Cells %v\n", final.CompletedTurns, len(final.Alive))
+			}
+		}
+	})
+	if *termVis {
+		viewer = term.Viewer{Options: term.Options{FPS: *fps}}
+	} else if !(*noVis) {
+		viewer = sdlViewer(*fps)
+	}
+	keyPresses := make(chan rune, 10)
+	events := make(chan gol.Event, 1000)
+	go recording.Play(rec, events, keyPresses, opts)
+	viewer.Visualise(p, events, keyPresses, nil)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestReportTurns checks that AliveCellsCount is sent every ReportTurns turns with the right counts
+// when the timed reports are turned off.
+func TestReportTurns(t *testing.T) {
+	p := gol.Params{Turns: 100, Threads: 4, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1, ReportTurns: 10}
+	alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+
+	reports := 0
+	_, err := runGame(p, func(event gol.Event) {
+		if e, ok := event.(gol.AliveCellsCount); ok {
+			reports++
+			if e.CompletedTurns != reports*p.ReportTurns {
+				t.Errorf("expected a report at turn %v, got one at turn %v", reports*p.ReportTurns, e.CompletedTurns)
+			}
+			if e.CellsCount != alive[e.CompletedTurns] {
+				t.Errorf("At turn %v expected %v alive cells, got %v instead", e.CompletedTurns, alive[e.CompletedTurns], e.CellsCount)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reports != p.Turns/p.ReportTurns {
+		t.Fatalf("expected %v AliveCellsCount events, got %v", p.Turns/p.ReportTurns, reports)
+	}
+}
+
+// TestReportWhilePaused checks that pressing 'a' while paused still sends an AliveCellsCount.
+func TestReportWhilePaused(t *testing.T) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1}
+	alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+	keyPresses := make(chan rune, 2)
+	keyPresses <- 'p'
+
+	reported := false
+	_, err := runGameWithKeys(p, keyPresses, func(event gol.Event) {
+		switch e := event.(type) {
+		case gol.StateChange:
+			if e.NewState == gol.Paused {
+				keyPresses <- 'a'
+			}
+		case gol.AliveCellsCount:
+			if e.CompletedTurns > 0 && e.CellsCount != alive[e.CompletedTurns] {
+				t.Errorf("At turn %v expected %v alive cells, got %v instead", e.CompletedTurns, alive[e.CompletedTurns], e.CellsCount)
+			}
+			if !reported {
+				reported = true
+				keyPresses <- 'q'
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reported {
+		t.Fatal("no AliveCellsCount event received while paused")
+	}
+}
+
+// TestReportSlowTurn checks that timed reports keep coming while a slow turn is being computed,
+// rather than only between turns.
+func TestReportSlowTurn(t *testing.T) {
+	p := gol.Params{Turns: 2, Threads: 1, ImageWidth: 2048, ImageHeight: 2048, ReportInterval: time.Millisecond}
+	p.InitialWorld = make([][]byte, p.ImageHeight)
+	for y := range p.InitialWorld {
+		p.InitialWorld[y] = make([]byte, p.ImageWidth)
+	}
+
+	reports := 0
+	var firstTurn, secondTurn time.Time
+	_, err := runGame(p, func(event gol.Event) {
+		switch e := event.(type) {
+		case gol.AliveCellsCount:
+			if e.CompletedTurns == 1 {
+				reports++
+			}
+		case gol.TurnComplete:
+			if e.CompletedTurns == 1 {
+				firstTurn = time.Now()
+			} else {
+				secondTurn = time.Now()
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//only a turn lasting many report intervals is sure to have reports while it runs
+	if took := secondTurn.Sub(firstTurn); took < 20*p.ReportInterval {
+		t.Skipf("the second turn only took %v, too quick to expect reports during it", took)
+	}
+	if reports < 2 {
+		t.Fatalf("expected reports during the second turn, got %v", reports)
+	}
+}
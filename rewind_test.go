@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestRewind checks that 'b' and 'f' move back and forwards through the history while paused,
+// that going back stops at the oldest kept turn, and that stepping on from a rewound turn carries on
+// from the board at that turn.
+func TestRewind(t *testing.T) {
+	tests := []struct {
+		keys    string
+		history int
+		turns   []int
+	}{
+		{"pnnnbbfbnq", gol.DefaultHistory, []int{1, 2, 3, 2, 1, 2, 1, 2}},
+		{"pnnbbbbnq", gol.DefaultHistory, []int{1, 2, 1, 0, 1}},
+		{"pnnnbbbq", 2, []int{1, 2, 3, 2, 1}},
+		{"pnnbfq", 0, []int{1, 2}},
+	}
+	for _, test := range tests {
+		t.Run(test.keys, func(t *testing.T) {
+			runRewind(t, test.keys, test.history, test.turns)
+		})
+	}
+}
+
+func runRewind(t *testing.T, keys string, history int, expected []int) {
+	p := gol.Params{Turns: 100000000, Threads: 4, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1, History: history}
+	alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+	alive[0] = len(readAliveCells("images/16x16.pgm", p.ImageWidth, p.ImageHeight))
+	keyPresses := make(chan rune, 10)
+	for _, k := range keys {
+		keyPresses <- k
+	}
+
+	var turns []int
+	board := make(map[util.Cell]bool)
+	_, err := runGameWithKeys(p, keyPresses, func(event gol.Event) {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			//follow the board through every flip, including those made by rewinding
+			if board[e.Cell] {
+				delete(board, e.Cell)
+			} else {
+				board[e.Cell] = true
+			}
+		case gol.TurnComplete:
+			turns = append(turns, e.CompletedTurns)
+			if len(board) != alive[e.CompletedTurns] {
+				t.Errorf("expected %v alive cells at turn %v, got %v", alive[e.CompletedTurns], e.CompletedTurns, len(board))
+			}
+		case gol.FinalTurnComplete:
+			last := expected[len(expected)-1]
+			if e.CompletedTurns != last || len(e.Alive) != alive[last] {
+				t.Errorf("expected %v alive cells at turn %v, got %v at turn %v", alive[last], last, len(e.Alive), e.CompletedTurns)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(turns) != len(expected) {
+		t.Fatalf("expected turns %v, got %v", expected, turns)
+	}
+	for i := range turns {
+		if turns[i] != expected[i] {
+			t.Fatalf("expected turns %v, got %v", expected, turns)
+		}
+	}
+}
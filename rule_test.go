@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestRule checks that Conway's Life written out in full Hensel notation matches the expected
+// 64x64 images, and that a non-totalistic rule gives the same board for every thread count.
+func TestRule(t *testing.T) {
+	p := gol.Params{ImageWidth: 64, ImageHeight: 64, Rule: "B3ceaiknjqry/S2-c2c3"}
+	for _, turns := range []int{0, 1, 100} {
+		p.Turns = turns
+		expectedAlive := readAliveCells(
+			"check/images/"+fmt.Sprintf("%vx%vx%v.pgm", p.ImageWidth, p.ImageHeight, turns),
+			p.ImageWidth,
+			p.ImageHeight,
+		)
+		for _, threads := range []int{1, 3, 8} {
+			p.Threads = threads
+			t.Run(fmt.Sprintf("%s-%dx%dx%d-%d", p.Rule, p.ImageWidth, p.ImageHeight, p.Turns, p.Threads), func(t *testing.T) {
+				alive, err := runGame(p, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertEqualBoard(t, alive, expectedAlive, p)
+			})
+		}
+	}
+
+	p = gol.Params{ImageWidth: 64, ImageHeight: 64, Turns: 50, Threads: 1, Rule: "B2-a/S12"}
+	expectedAlive, err := runGame(p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for threads := 2; threads <= 16; threads++ {
+		p.Threads = threads
+		t.Run(fmt.Sprintf("%s-%dx%dx%d-%d", p.Rule, p.ImageWidth, p.ImageHeight, p.Turns, p.Threads), func(t *testing.T) {
+			alive, err := runGame(p, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEqualBoard(t, alive, expectedAlive, p)
+		})
+	}
+}
+
+// TestParseRule checks that malformed rule strings are rejected.
+func TestParseRule(t *testing.T) {
+	for _, rule := range []string{"B3/S23", "b36/s23", "B2-a/S12", "B3/S2ae3", "B2/S"} {
+		if _, err := gol.ParseRule(rule); err != nil {
+			t.Errorf("rule %v should be valid, got %v", rule, err)
+		}
+	}
+	for _, rule := range []string{"B3", "B9/S23", "X3/S23", "B2-/S1", "B1a/S23", "B0c/S23", "B4x/S23", "B3/B2", "S23/S23"} {
+		if _, err := gol.ParseRule(rule); err == nil {
+			t.Errorf("rule %v should be invalid", rule)
+		}
+	}
+}
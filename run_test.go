@@ -0,0 +1,44 @@
+package main
+
+import (
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// runGame runs a game with no keys pressed until the events channel is closed, passing every event
+// to seen if it isn't nil. It returns the alive cells from FinalTurnComplete and the error from gol.Run.
+func runGame(p gol.Params, seen func(gol.Event)) ([]util.Cell, error) {
+	return runGameWithKeys(p, nil, seen)
+}
+
+// runGameWithKeys is like runGame, but keys sent on keyPresses, e.g. by seen, are pressed in the game.
+// keyPresses should be buffered so that seen doesn't block.
+func runGameWithKeys(p gol.Params, keyPresses <-chan rune, seen func(gol.Event)) ([]util.Cell, error) {
+	return runGameWithEdits(p, keyPresses, nil, seen)
+}
+
+// runGameWithEdits is like runGameWithKeys, but cells can also be edited by sending on edits,
+// which should be buffered too.
+func runGameWithEdits(p gol.Params, keyPresses <-chan rune, edits <-chan gol.CellEdit, seen func(gol.Event)) ([]util.Cell, error) {
+	events := make(chan gol.Event)
+	result := make(chan error, 1)
+	go func() {
+		result <- gol.RunWithEdits(p, events, keyPresses, edits)
+	}()
+	var alive []util.Cell
+	for event := range events {
+		if seen != nil {
+			seen(event)
+		}
+		if final, ok := event.(gol.FinalTurnComplete); ok {
+			alive = final.Alive
+		}
+	}
+	return alive, <-result
+}
+
+// withInitial returns a copy of p changed by set, e.g. to give it an initial world.
+func withInitial(p gol.Params, set func(p *gol.Params)) gol.Params {
+	set(&p)
+	return p
+}
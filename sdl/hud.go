@@ -0,0 +1,150 @@
+//go:build cgo && !nosdl
+// +build cgo,!nosdl
+
+package sdl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// hudScale is the size in screen pixels of one pixel of the HUD font.
+const hudScale = 2
+
+// tpsInterval is how often the turns per second shown in the HUD are worked out again.
+const tpsInterval = 500 * time.Millisecond
+
+// hud keeps track of what the heads-up display shows: it is updated from the events the viewer receives.
+type hud struct {
+	turn  int
+	alive int
+	tps   float64
+	state gol.State
+	rule  string
+
+	lastTurn int
+	lastTime time.Time
+}
+
+func newHud(p gol.Params) *hud {
+	rule := p.Rule
+	if rule == "" {
+		rule = gol.DefaultRule
+	}
+	return &hud{state: gol.Executing, rule: rule, lastTime: time.Now()}
+}
+
+// turnComplete records a TurnComplete event, working out the throughput every tpsInterval.
+func (h *hud) turnComplete(turn int) {
+	h.turn = turn
+	if elapsed := time.Since(h.lastTime); elapsed >= tpsInterval {
+		h.tps = float64(turn-h.lastTurn) / elapsed.Seconds()
+		h.lastTurn = turn
+		h.lastTime = time.Now()
+	}
+}
+
+// stateChange records a StateChange event. Nothing is computed while paused, so the throughput drops to 0.
+func (h *hud) stateChange(state gol.State, turn int) {
+	h.state = state
+	h.turn = turn
+	if state != gol.Executing {
+		h.tps = 0
+	}
+	h.lastTurn = turn
+	h.lastTime = time.Now()
+}
+
+func (h *hud) String() string {
+	return fmt.Sprintf("Turn %v | Alive %v | %.1f turns/s | %v | %v", h.turn, h.alive, h.tps, h.state, h.rule)
+}
+
+// font is a tiny 3x5 pixel font for the HUD, covering digits, capital letters and a few symbols.
+// Lower case letters are drawn as capitals and anything else as a space.
+var font = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", ".##", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	'N': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#.#", "#.#", "###", "###", "#.#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+	'/': {"..#", "..#", ".#.", "#..", "#.."},
+	'-': {"...", "...", "###", "...", "..."},
+	'.': {"...", "...", "...", "...", ".#."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'|': {".#.", ".#.", ".#.", ".#.", ".#."},
+}
+
+// drawText draws text with the HUD font on a dark bar across the top of the window.
+func (w *Window) drawText(text string) {
+	text = strings.ToUpper(text)
+	barHeight := int32(7 * hudScale)
+	err := w.renderer.SetDrawBlendMode(sdl.BLENDMODE_BLEND)
+	util.Check(err)
+	err = w.renderer.SetDrawColor(0, 0, 0, 170)
+	util.Check(err)
+	err = w.renderer.FillRect(&sdl.Rect{X: 0, Y: 0, W: int32(w.view.screenWidth), H: barHeight})
+	util.Check(err)
+
+	var rects []sdl.Rect
+	x := int32(hudScale)
+	for _, r := range text {
+		glyph, ok := font[r]
+		if ok {
+			for gy, row := range glyph {
+				for gx, c := range row {
+					if c == '#' {
+						rects = append(rects, sdl.Rect{
+							X: x + int32(gx*hudScale),
+							Y: int32((gy + 1) * hudScale),
+							W: hudScale,
+							H: hudScale,
+						})
+					}
+				}
+			}
+		}
+		x += 4 * hudScale
+	}
+	err = w.renderer.SetDrawColor(255, 255, 255, 255)
+	util.Check(err)
+	if len(rects) > 0 {
+		err = w.renderer.FillRects(rects)
+		util.Check(err)
+	}
+}
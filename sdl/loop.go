@@ -1,16 +1,64 @@
+//go:build cgo && !nosdl
+// +build cgo,!nosdl
+
 package sdl
 
 import (
 	"fmt"
+
 	"github.com/veandco/go-sdl2/sdl"
 	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+	"uk.ac.bris.cs/gameoflife/visual"
 )
 
-func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune) {
+// panStep is how far the arrow keys move the view, as a fraction of the window.
+const panStep = 0.1
+
+// zoomStep is how much one notch of the mouse wheel (or Page Up/Down) zooms by.
+const zoomStep = 1.25
+
+// Options are the viewer settings that can be chosen on the command line.
+type Options struct {
+	Palette string  // name of one of the Palettes
+	AgeMode bool    // colour cells by how long they have been alive or dead
+	FPS     float64 // most frames to draw per second, skipping turns in between. 0 draws every turn
+}
+
+func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit, opts Options) {
 	w := NewWindow(int32(p.ImageWidth), int32(p.ImageHeight))
+	w.SetPalette(opts.Palette, opts.AgeMode)
+
+	//the status bar is brought up to date whenever a frame is drawn
+	h := newHud(p)
+	frames := visual.NewFrameLimiter(opts.FPS)
+	render := func() {
+		h.alive = w.CountPixels()
+		w.SetStatus(h.String())
+		w.RenderFrame()
+		frames.Drawn()
+	}
+
+	paused := false
+	painting := false        //the left mouse button is held down
+	paintAlive := false      //what the cells under the mouse are set to while painting
+	var lastCell util.Cell   //the last cell painted, so a drag doesn't keep toggling the same cell
+	var mouseX, mouseY int32 //where the mouse is in the window, to zoom around it
+	//edits wait here until the game has room for them. Sending straight away could block while the game
+	//is blocked sending the events for earlier edits, and then neither side would ever carry on.
+	var pendingEdits []gol.CellEdit
 
 sdlLoop:
 	for {
+	sendEdits:
+		for len(pendingEdits) > 0 {
+			select {
+			case edits <- pendingEdits[0]:
+				pendingEdits = pendingEdits[1:]
+			default:
+				break sendEdits
+			}
+		}
 		event := w.PollEvent()
 		if event != nil {
 			switch e := event.(type) {
@@ -24,6 +72,88 @@ sdlLoop:
 					keyPresses <- 'q'
 				case sdl.K_k:
 					keyPresses <- 'k'
+				case sdl.K_c:
+					keyPresses <- 'c'
+				case sdl.K_a:
+					keyPresses <- 'a'
+				case sdl.K_n:
+					keyPresses <- 'n'
+				case sdl.K_b:
+					keyPresses <- 'b'
+				case sdl.K_f:
+					keyPresses <- 'f'
+				case sdl.K_PLUS, sdl.K_EQUALS, sdl.K_KP_PLUS:
+					keyPresses <- '+'
+				case sdl.K_MINUS, sdl.K_KP_MINUS:
+					keyPresses <- '-'
+				//moving around the board doesn't involve the distributor
+				case sdl.K_LEFT:
+					w.Pan(-panStep, 0)
+					render()
+				case sdl.K_RIGHT:
+					w.Pan(panStep, 0)
+					render()
+				case sdl.K_UP:
+					w.Pan(0, -panStep)
+					render()
+				case sdl.K_DOWN:
+					w.Pan(0, panStep)
+					render()
+				case sdl.K_PAGEUP:
+					w.ZoomCentre(zoomStep)
+					render()
+				case sdl.K_PAGEDOWN:
+					w.ZoomCentre(1 / zoomStep)
+					render()
+				case sdl.K_HOME:
+					w.ResetView()
+					render()
+				case sdl.K_v:
+					w.NextPalette()
+					render()
+				case sdl.K_g:
+					w.ToggleAgeMode()
+					render()
+				case sdl.K_h:
+					w.ToggleStatusBar()
+					render()
+				}
+			case *sdl.MouseWheelEvent:
+				if e.Y > 0 {
+					w.Zoom(zoomStep, mouseX, mouseY)
+				} else if e.Y < 0 {
+					w.Zoom(1/zoomStep, mouseX, mouseY)
+				}
+				render()
+			case *sdl.MouseButtonEvent:
+				//without an edits channel (e.g. in a replay) the board can't be changed
+				if e.Button != sdl.BUTTON_LEFT || edits == nil {
+					break
+				}
+				if e.Type == sdl.MOUSEBUTTONDOWN {
+					x, y, ok := w.CellAt(e.X, e.Y)
+					if ok {
+						//clicking toggles the cell, dragging paints the rest the same way
+						cell := util.Cell{X: x, Y: y}
+						painting = true
+						paintAlive = !w.IsPixelSet(cell.X, cell.Y)
+						lastCell = cell
+						pendingEdits = append(pendingEdits, gol.CellEdit{Cell: cell, Alive: paintAlive})
+					}
+				} else {
+					painting = false
+				}
+			case *sdl.MouseMotionEvent:
+				mouseX, mouseY = e.X, e.Y
+				if painting {
+					x, y, _ := w.CellAt(e.X, e.Y)
+					cell := util.Cell{X: x, Y: y}
+					for _, c := range cellsBetween(lastCell, cell) {
+						if w.InBounds(c.X, c.Y) {
+							pendingEdits = append(pendingEdits, gol.CellEdit{Cell: c, Alive: paintAlive})
+						}
+					}
+					lastCell = cell
 				}
 			}
 		}
@@ -35,20 +165,67 @@ sdlLoop:
 			}
 			switch e := event.(type) {
 			case gol.CellFlipped:
-				w.FlipPixel(e.Cell.X, e.Cell.Y)
+				w.FlipPixel(e.Cell.X, e.Cell.Y, e.CompletedTurns)
+				//no TurnComplete will come while paused, so show edits straight away
+				if paused {
+					render()
+				}
 			case gol.TurnComplete:
-				w.RenderFrame()
+				h.turnComplete(e.CompletedTurns)
+				w.SetTurn(e.CompletedTurns)
+				if frames.TurnComplete() {
+					render()
+				}
 			case gol.FinalTurnComplete:
 				w.Destroy()
 				break sdlLoop
+			case gol.StateChange:
+				paused = e.NewState == gol.Paused
+				h.stateChange(e.NewState, e.CompletedTurns)
+				render()
+				fmt.Printf("Completed Turns %-8v%v\n", event.GetCompletedTurns(), event)
 			default:
 				if len(event.String()) > 0 {
 					fmt.Printf("Completed Turns %-8v%v\n", event.GetCompletedTurns(), event)
 				}
 			}
 		default:
-			break
+			if frames.CatchUp() {
+				render()
+			}
 		}
 	}
 
 }
+
+// cellsBetween lists the cells on a straight line from a (not included) to b (included),
+// so that a fast drag doesn't leave gaps.
+func cellsBetween(a, b util.Cell) []util.Cell {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	steps := abs(dx)
+	if abs(dy) > steps {
+		steps = abs(dy)
+	}
+	var cells []util.Cell
+	for i := 1; i <= steps; i++ {
+		cells = append(cells, util.Cell{X: a.X + dx*i/steps, Y: a.Y + dy*i/steps})
+	}
+	return cells
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Viewer shows the game in an SDL window. It is a visual.Visualiser.
+type Viewer struct {
+	Options Options
+}
+
+// Visualise runs the window with v's options until the final turn.
+func (v Viewer) Visualise(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+	Run(p, events, keyPresses, edits, v.Options)
+}
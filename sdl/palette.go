@@ -0,0 +1,83 @@
+//go:build cgo && !nosdl
+// +build cgo,!nosdl
+
+package sdl
+
+import "strings"
+
+// ageSpan is how many turns it takes an alive cell to go from the Young to the Old colour in age mode.
+const ageSpan = 64
+
+// fadeSpan is how many turns a dead cell takes to fade from the Dying to the Dead colour in age mode.
+const fadeSpan = 16
+
+// neverFlipped is the flip turn recorded for cells that haven't changed yet, so they look long dead.
+const neverFlipped = -1 << 30
+
+// colour is an opaque RGB colour.
+type colour struct {
+	R, G, B uint8
+}
+
+// Palette is a colour scheme for the viewer. Dead and Alive are used normally. In age mode alive cells
+// go from Young to Old the longer they live, and cells that just died fade from Dying to Dead.
+type Palette struct {
+	Name              string
+	Dead, Alive       colour
+	Young, Old, Dying colour
+}
+
+// Palettes are the colour schemes that can be picked with the -palette flag or cycled with 'v'.
+var Palettes = []Palette{
+	{"classic", colour{0, 0, 0}, colour{255, 255, 255}, colour{255, 255, 255}, colour{90, 120, 255}, colour{70, 0, 0}},
+	{"phosphor", colour{0, 12, 0}, colour{60, 255, 60}, colour{200, 255, 120}, colour{0, 110, 40}, colour{0, 50, 20}},
+	{"paper", colour{250, 250, 245}, colour{20, 20, 20}, colour{200, 30, 30}, colour{20, 20, 20}, colour{220, 210, 200}},
+	{"heat", colour{0, 0, 20}, colour{255, 200, 0}, colour{255, 255, 180}, colour{200, 0, 0}, colour{40, 0, 60}},
+}
+
+// PaletteNames lists the names of all Palettes, for help text.
+func PaletteNames() string {
+	names := make([]string, len(Palettes))
+	for i, palette := range Palettes {
+		names[i] = palette.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// findPalette returns the index of the palette with the given name, or 0 (classic) if there isn't one.
+func findPalette(name string) int {
+	for i, palette := range Palettes {
+		if palette.Name == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// cellColour works out the colour of a cell. age is how many turns ago it last flipped.
+func (palette *Palette) cellColour(alive, ageMode bool, age int) colour {
+	if !ageMode {
+		if alive {
+			return palette.Alive
+		}
+		return palette.Dead
+	}
+	if alive {
+		return mix(palette.Young, palette.Old, age, ageSpan)
+	}
+	return mix(palette.Dying, palette.Dead, age, fadeSpan)
+}
+
+// mix blends from a to b as step goes from 0 to steps, staying at b after that.
+func mix(a, b colour, step, steps int) colour {
+	if step >= steps {
+		return b
+	}
+	if step < 0 {
+		step = 0
+	}
+	blend := func(x, y uint8) uint8 {
+		return uint8((int(x)*(steps-step) + int(y)*step) / steps)
+	}
+	return colour{blend(a.R, b.R), blend(a.G, b.G), blend(a.B, b.B)}
+}
@@ -0,0 +1,115 @@
+//go:build cgo && !nosdl
+// +build cgo,!nosdl
+
+package sdl
+
+import "math"
+
+// maxWindowSize is the largest the window is made in either direction when it opens.
+const maxWindowSize = 800
+
+// maxZoom is the most screen pixels a single cell can be zoomed to.
+const maxZoom = 64
+
+// view maps board cells to screen pixels. zoom is the number of screen pixels per cell, which is
+// below 1 when the board is bigger than the window. offsetX and offsetY are the board position
+// shown at the top left of the window, in cells.
+type view struct {
+	boardWidth, boardHeight   int
+	screenWidth, screenHeight int
+	zoom                      float64
+	offsetX, offsetY          float64
+}
+
+// newView picks a window size for the board: small boards are scaled up by a whole number,
+// large ones are shrunk to fit in maxWindowSize.
+func newView(boardWidth, boardHeight int) *view {
+	v := &view{boardWidth: boardWidth, boardHeight: boardHeight}
+	fit := math.Min(float64(maxWindowSize)/float64(boardWidth), float64(maxWindowSize)/float64(boardHeight))
+	if fit >= 1 {
+		fit = math.Floor(fit)
+	}
+	v.screenWidth = int(math.Ceil(float64(boardWidth) * fit))
+	v.screenHeight = int(math.Ceil(float64(boardHeight) * fit))
+	v.reset()
+	return v
+}
+
+// minZoom is how far out the view can go: far enough to see the whole board.
+func (v *view) minZoom() float64 {
+	return math.Min(float64(v.screenWidth)/float64(v.boardWidth), float64(v.screenHeight)/float64(v.boardHeight))
+}
+
+// reset shows the whole board again.
+func (v *view) reset() {
+	v.zoom = v.minZoom()
+	v.clamp()
+}
+
+// zoomAt multiplies the zoom by factor, keeping the cell under the screen position (sx, sy) still.
+func (v *view) zoomAt(factor float64, sx, sy int) {
+	cellX := v.offsetX + float64(sx)/v.zoom
+	cellY := v.offsetY + float64(sy)/v.zoom
+	v.zoom = math.Max(v.minZoom(), math.Min(maxZoom, v.zoom*factor))
+	v.offsetX = cellX - float64(sx)/v.zoom
+	v.offsetY = cellY - float64(sy)/v.zoom
+	v.clamp()
+}
+
+// pan moves the view by the given number of screen pixels.
+func (v *view) pan(dx, dy int) {
+	v.offsetX += float64(dx) / v.zoom
+	v.offsetY += float64(dy) / v.zoom
+	v.clamp()
+}
+
+// clamp keeps the view on the board, centring the board if it is smaller than the window.
+func (v *view) clamp() {
+	v.offsetX = clampOffset(v.offsetX, float64(v.screenWidth)/v.zoom, float64(v.boardWidth))
+	v.offsetY = clampOffset(v.offsetY, float64(v.screenHeight)/v.zoom, float64(v.boardHeight))
+}
+
+func clampOffset(offset, visible, board float64) float64 {
+	if visible >= board {
+		return (board - visible) / 2
+	}
+	return math.Max(0, math.Min(board-visible, offset))
+}
+
+// cellAt returns the cell under the screen position (sx, sy), and false if it is off the board.
+func (v *view) cellAt(sx, sy int) (int, int, bool) {
+	x := int(math.Floor(v.offsetX + float64(sx)/v.zoom))
+	y := int(math.Floor(v.offsetY + float64(sy)/v.zoom))
+	return x, y, x >= 0 && y >= 0 && x < v.boardWidth && y < v.boardHeight
+}
+
+// visibleCells returns the range of cells that are at least partly on screen, end exclusive.
+func (v *view) visibleCells() (x0, y0, x1, y1 int) {
+	x0 = int(math.Max(0, math.Floor(v.offsetX)))
+	y0 = int(math.Max(0, math.Floor(v.offsetY)))
+	x1 = int(math.Min(float64(v.boardWidth), math.Ceil(v.offsetX+float64(v.screenWidth)/v.zoom)))
+	y1 = int(math.Min(float64(v.boardHeight), math.Ceil(v.offsetY+float64(v.screenHeight)/v.zoom)))
+	return
+}
+
+// toScreen returns the screen position of the top left corner of cell (x, y).
+func (v *view) toScreen(x, y int) (int, int) {
+	return int(math.Round((float64(x) - v.offsetX) * v.zoom)), int(math.Round((float64(y) - v.offsetY) * v.zoom))
+}
+
+// cellRange returns the cells covered by screen pixels [s, s+1) along one axis, end exclusive,
+// always including at least one cell. It is used to shade the view when zoomed out.
+func (v *view) cellRange(s int, offset float64, board int) (int, int) {
+	start := int(math.Floor(offset + float64(s)/v.zoom))
+	end := int(math.Floor(offset + float64(s+1)/v.zoom))
+	if end <= start {
+		end = start + 1
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > board {
+		end = board
+	}
+	return start, end
+}
@@ -1,3 +1,6 @@
+//go:build cgo && !nosdl
+// +build cgo,!nosdl
+
 package sdl
 
 import (
@@ -8,44 +11,75 @@ import (
 )
 
 type Window struct {
-	Width, Height int32
+	Width, Height int32 // size of the board, one pixel per cell
 	window        *sdl.Window
 	renderer      *sdl.Renderer
 	texture       *sdl.Texture
 	pixels        []byte
+	alive         []bool // the state of each cell, pixels only holds their colours
+	flippedAt     []int  // the turn each cell last changed state, for age mode
+	turn          int
+	paletteIndex  int
+	ageMode       bool
+	aliveCount    int
+	status        string // shown in the title and, if showStatus is set, in a bar over the board
+	showStatus    bool
+	view          *view
+	shaded        *sdl.Texture // screen sized, used when zoomed out so several cells share a pixel
+	shadedPixels  []byte
 }
 
 func filterEvent(e sdl.Event, userdata interface{}) bool {
-	return e.GetType() == sdl.KEYDOWN || e.GetType() == sdl.QUIT
+	switch e.GetType() {
+	case sdl.KEYDOWN, sdl.QUIT, sdl.MOUSEBUTTONDOWN, sdl.MOUSEBUTTONUP, sdl.MOUSEMOTION, sdl.MOUSEWHEEL:
+		return true
+	}
+	return false
 }
 
+// NewWindow opens a window for a board of the given size. Small boards are scaled up and
+// large ones shrunk to fit on screen; the view can then be zoomed and panned.
 func NewWindow(width, height int32) *Window {
+	v := newView(int(width), int(height))
+	screenWidth, screenHeight := int32(v.screenWidth), int32(v.screenHeight)
+
 	err := sdl.Init(sdl.INIT_EVERYTHING)
 	util.Check(err)
-	window, err := sdl.CreateWindow("GOL GUI", sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED, width, height, sdl.WINDOW_SHOWN)
+	window, err := sdl.CreateWindow("GOL GUI", sdl.WINDOWPOS_CENTERED, sdl.WINDOWPOS_CENTERED, screenWidth, screenHeight, sdl.WINDOW_SHOWN)
 	util.Check(err)
 	renderer, err := sdl.CreateRenderer(window, -1, sdl.WINDOW_SHOWN)
 	util.Check(err)
-	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "linear")
-	err = renderer.SetLogicalSize(width, height)
-	util.Check(err)
+	//cells should stay sharp squares when zoomed in
+	sdl.SetHint(sdl.HINT_RENDER_SCALE_QUALITY, "nearest")
 	texture, err := renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STATIC, width, height)
 	util.Check(err)
+	shaded, err := renderer.CreateTexture(sdl.PIXELFORMAT_ARGB8888, sdl.TEXTUREACCESS_STATIC, screenWidth, screenHeight)
+	util.Check(err)
 
 	sdl.SetEventFilterFunc(filterEvent, nil)
-	return &Window{
-		width,
-		height,
-		window,
-		renderer,
-		texture,
-		make([]byte, width*height*4),
+	w := &Window{
+		Width:        width,
+		Height:       height,
+		window:       window,
+		renderer:     renderer,
+		texture:      texture,
+		pixels:       make([]byte, width*height*4),
+		alive:        make([]bool, width*height),
+		flippedAt:    make([]int, width*height),
+		view:         v,
+		shaded:       shaded,
+		shadedPixels: make([]byte, screenWidth*screenHeight*4),
+		showStatus:   true,
 	}
+	w.ClearPixels()
+	return w
 }
 
 func (w *Window) Destroy() {
 	err := w.texture.Destroy()
 	util.Check(err)
+	err = w.shaded.Destroy()
+	util.Check(err)
 	err = w.renderer.Destroy()
 	util.Check(err)
 	err = w.window.Destroy()
@@ -54,51 +88,207 @@ func (w *Window) Destroy() {
 }
 
 func (w *Window) RenderFrame() {
-	err := w.texture.Update(nil, w.pixels, int(w.Width*4))
+	if w.ageMode {
+		//every cell's colour changes as it gets older
+		w.recolour()
+	}
+	//anything not covered by the board is drawn as dead cells
+	dead := w.palette().Dead
+	err := w.renderer.SetDrawColor(dead.R, dead.G, dead.B, 0xFF)
 	util.Check(err)
 	err = w.renderer.Clear()
 	util.Check(err)
-	err = w.renderer.Copy(w.texture, nil, nil)
+	if w.view.zoom >= 1 {
+		//every visible cell gets at least a pixel, so let SDL scale the board up
+		err = w.texture.Update(nil, w.pixels, int(w.Width*4))
+		util.Check(err)
+		x0, y0, x1, y1 := w.view.visibleCells()
+		sx0, sy0 := w.view.toScreen(x0, y0)
+		sx1, sy1 := w.view.toScreen(x1, y1)
+		src := &sdl.Rect{X: int32(x0), Y: int32(y0), W: int32(x1 - x0), H: int32(y1 - y0)}
+		dst := &sdl.Rect{X: int32(sx0), Y: int32(sy0), W: int32(sx1 - sx0), H: int32(sy1 - sy0)}
+		err = w.renderer.Copy(w.texture, src, dst)
+	} else {
+		//several cells share each pixel, so shade it by how many of them are alive
+		w.shade()
+		err = w.shaded.Update(nil, w.shadedPixels, w.view.screenWidth*4)
+		util.Check(err)
+		err = w.renderer.Copy(w.shaded, nil, nil)
+	}
 	util.Check(err)
+	if w.showStatus && w.status != "" {
+		w.drawText(w.status)
+	}
 	w.renderer.Present()
 }
 
+// shade fills shadedPixels with the average colour of the cells under each screen pixel.
+func (w *Window) shade() {
+	v := w.view
+	width := int(w.Width)
+	for sy := 0; sy < v.screenHeight; sy++ {
+		y0, y1 := v.cellRange(sy, v.offsetY, v.boardHeight)
+		for sx := 0; sx < v.screenWidth; sx++ {
+			x0, x1 := v.cellRange(sx, v.offsetX, v.boardWidth)
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					i := 4 * (y*width + x)
+					sum[0] += int(w.pixels[i+0])
+					sum[1] += int(w.pixels[i+1])
+					sum[2] += int(w.pixels[i+2])
+					sum[3] += int(w.pixels[i+3])
+				}
+			}
+			count := (x1 - x0) * (y1 - y0)
+			i := 4 * (sy*v.screenWidth + sx)
+			for c := 0; c < 4; c++ {
+				if count > 0 {
+					w.shadedPixels[i+c] = byte(sum[c] / count)
+				} else {
+					w.shadedPixels[i+c] = 0
+				}
+			}
+		}
+	}
+}
+
+// CellAt returns the cell under a position in the window, and false if there isn't one.
+func (w *Window) CellAt(x, y int32) (int, int, bool) {
+	return w.view.cellAt(int(x), int(y))
+}
+
+// Zoom zooms in (factor above 1) or out, keeping the cell under the window position (x, y) still.
+func (w *Window) Zoom(factor float64, x, y int32) {
+	w.view.zoomAt(factor, int(x), int(y))
+}
+
+// ZoomCentre zooms in or out around the middle of the window.
+func (w *Window) ZoomCentre(factor float64) {
+	w.view.zoomAt(factor, w.view.screenWidth/2, w.view.screenHeight/2)
+}
+
+// Pan moves the view by a fraction of the window size, e.g. Pan(0.1, 0) moves a tenth of the way right.
+func (w *Window) Pan(fx, fy float64) {
+	w.view.pan(int(fx*float64(w.view.screenWidth)), int(fy*float64(w.view.screenHeight)))
+}
+
+// ResetView zooms back out to show the whole board.
+func (w *Window) ResetView() {
+	w.view.reset()
+}
+
 func (w *Window) PollEvent() sdl.Event {
 	return sdl.PollEvent()
 }
 
 func (w *Window) SetPixel(x, y int) {
-	width := int(w.Width)
-	w.pixels[4*(y*width+x)+0] = 0xFF
-	w.pixels[4*(y*width+x)+1] = 0xFF
-	w.pixels[4*(y*width+x)+2] = 0xFF
-	w.pixels[4*(y*width+x)+3] = 0xFF
+	i := y*int(w.Width) + x
+	if !w.alive[i] {
+		w.aliveCount++
+	}
+	w.alive[i] = true
+	w.flippedAt[i] = w.turn
+	w.colourPixel(i)
 }
 
-func (w *Window) FlipPixel(x, y int) {
+// FlipPixel changes the state of the cell at (x, y), which happened during the given turn.
+func (w *Window) FlipPixel(x, y, turn int) {
 	if x < 0 || y < 0 || x >= int(w.Width) || y >= int(w.Height) {
 		panic(fmt.Sprintf("CellFlipped event at (%d, %d) is outside the bounds of the window.", x, y))
 	}
 
-	width := int(w.Width)
-	w.pixels[4*(y*width+x)+0] = ^w.pixels[4*(y*width+x)+0]
-	w.pixels[4*(y*width+x)+1] = ^w.pixels[4*(y*width+x)+1]
-	w.pixels[4*(y*width+x)+2] = ^w.pixels[4*(y*width+x)+2]
-	w.pixels[4*(y*width+x)+3] = ^w.pixels[4*(y*width+x)+3]
+	i := y*int(w.Width) + x
+	w.alive[i] = !w.alive[i]
+	if w.alive[i] {
+		w.aliveCount++
+	} else {
+		w.aliveCount--
+	}
+	w.flippedAt[i] = turn
+	if turn > w.turn {
+		w.turn = turn
+	}
+	w.colourPixel(i)
 }
 
-func (w *Window) CountPixels() int {
-	count := 0
-	for i := 0; i < int(w.Width) * int(w.Height) * 4; i += 4 {
-		if w.pixels[i] == 0xFF {
-			count++
-		}
+// colourPixel writes the colour of cell i into the pixel buffer.
+func (w *Window) colourPixel(i int) {
+	c := w.palette().cellColour(w.alive[i], w.ageMode, w.turn-w.flippedAt[i])
+	//ARGB8888 is stored as B, G, R, A in memory
+	w.pixels[4*i+0] = c.B
+	w.pixels[4*i+1] = c.G
+	w.pixels[4*i+2] = c.R
+	w.pixels[4*i+3] = 0xFF
+}
+
+// recolour redraws every pixel, e.g. after the palette changes or as cells age.
+func (w *Window) recolour() {
+	for i := range w.alive {
+		w.colourPixel(i)
 	}
-	return count
+}
+
+func (w *Window) palette() *Palette {
+	return &Palettes[w.paletteIndex]
+}
+
+// SetTurn moves the window on to a completed turn, so ages are worked out from it.
+func (w *Window) SetTurn(turn int) {
+	w.turn = turn
+}
+
+// SetPalette picks the palette with the given name (see Palettes) and whether to colour cells by age.
+func (w *Window) SetPalette(name string, ageMode bool) {
+	w.paletteIndex = findPalette(name)
+	w.ageMode = ageMode
+	w.recolour()
+}
+
+// NextPalette switches to the next palette in Palettes.
+func (w *Window) NextPalette() {
+	w.paletteIndex = (w.paletteIndex + 1) % len(Palettes)
+	w.recolour()
+}
+
+// ToggleAgeMode switches between plain and age based colouring.
+func (w *Window) ToggleAgeMode() {
+	w.ageMode = !w.ageMode
+	w.recolour()
+}
+
+// InBounds reports whether (x, y) is a pixel of the window.
+func (w *Window) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < int(w.Width) && y < int(w.Height)
+}
+
+// IsPixelSet reports whether the pixel at (x, y) is currently showing an alive cell.
+func (w *Window) IsPixelSet(x, y int) bool {
+	return w.alive[y*int(w.Width)+x]
+}
+
+func (w *Window) CountPixels() int {
+	return w.aliveCount
 }
 
 func (w *Window) ClearPixels() {
-	for i := range w.pixels {
-		w.pixels[i] = 0
+	for i := range w.alive {
+		w.alive[i] = false
+		w.flippedAt[i] = neverFlipped
+	}
+	w.aliveCount = 0
+	w.recolour()
+}
+
+// SetStatus shows a line of text in the window title and the status bar.
+func (w *Window) SetStatus(text string) {
+	if text != w.status {
+		w.window.SetTitle("GOL GUI - " + text)
 	}
+	w.status = text
+}
+
+// ToggleStatusBar shows or hides the status bar drawn over the board. The title always shows the status.
+func (w *Window) ToggleStatusBar() {
+	w.showStatus = !w.showStatus
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestSnapshotComplete checks that by the time ImageOutputComplete arrives the image can already be
+// read back from out/, without waiting for the game to finish.
+func TestSnapshotComplete(t *testing.T) {
+	p := gol.Params{Turns: 100, Threads: 8, ImageWidth: 512, ImageHeight: 512}
+	expected := readAliveCells("check/images/512x512x100.pgm", p.ImageWidth, p.ImageHeight)
+
+	saves := 0
+	_, err := runGame(p, func(event gol.Event) {
+		if e, ok := event.(gol.ImageOutputComplete); ok {
+			saves++
+			cells := readAliveCells(fmt.Sprintf("out/%v.pgm", e.Filename), p.ImageWidth, p.ImageHeight)
+			assertEqualBoard(t, cells, expected, p)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saves != 1 {
+		t.Errorf("expected 1 image saved, got %v", saves)
+	}
+}
+
+// TestFinalSaveOrder checks that the final image is reported saved before FinalTurnComplete,
+// so visualisers that stop at FinalTurnComplete don't miss it.
+func TestFinalSaveOrder(t *testing.T) {
+	p := gol.Params{Turns: 10, Threads: 8, ImageWidth: 512, ImageHeight: 512}
+	saved, final := false, false
+	_, err := runGame(p, func(event gol.Event) {
+		switch event.(type) {
+		case gol.ImageOutputComplete:
+			if final {
+				t.Error("ImageOutputComplete sent after FinalTurnComplete")
+			}
+			saved = true
+		case gol.FinalTurnComplete:
+			final = true
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved {
+		t.Error("expected ImageOutputComplete for the final turn")
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// TestTargetTPS checks that the distributor is held back to the target number of turns per second.
+func TestTargetTPS(t *testing.T) {
+	p := gol.Params{Turns: 20, Threads: 4, ImageWidth: 16, ImageHeight: 16, TargetTPS: 100}
+	start := time.Now()
+	if _, err := runGame(p, nil); err != nil {
+		t.Fatal(err)
+	}
+	elapsed := time.Since(start)
+	//the first turn starts straight away, so 20 turns need at least 19 gaps of 10ms
+	if elapsed < 190*time.Millisecond {
+		t.Errorf("expected %v turns at %v turns/s to take at least 190ms, took %v", p.Turns, p.TargetTPS, elapsed)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/visual"
+)
+
+// statsWriter is a visualiser that writes the stats CSV file using writeStats.
+func statsWriter(filename string) visual.Visualiser {
+	return visual.Func(func(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+		for range writeStats(filename, events) {
+		}
+	})
+}
+
+// writeStats writes a row to a CSV file for every TurnStats event, in the same style as check/alive.
+// All events are passed on unchanged through the returned channel. If the file can't be written
+// the error is printed and no more rows are written, but the events still go through as the game doesn't depend on them.
+func writeStats(filename string, events <-chan gol.Event) <-chan gol.Event {
+	out := make(chan gol.Event, cap(events))
+	go func() {
+		defer close(out)
+		var writer *csv.Writer //nil once writing has failed
+		file, err := os.Create(filename)
+		if err == nil {
+			defer file.Close()
+			writer = csv.NewWriter(file)
+			err = writer.Write([]string{"completed_turns", "alive_cells", "births", "deaths", "density", "min_x", "min_y", "max_x", "max_y"})
+		}
+		failed := func(err error) {
+			fmt.Println("Error writing stats:", err)
+			writer = nil
+		}
+		if err != nil {
+			failed(err)
+		}
+		for event := range events {
+			switch e := event.(type) {
+			case gol.TurnStats:
+				if writer == nil {
+					break
+				}
+				err := writer.Write([]string{
+					strconv.Itoa(e.CompletedTurns),
+					strconv.Itoa(e.Alive),
+					strconv.Itoa(e.Births),
+					strconv.Itoa(e.Deaths),
+					strconv.FormatFloat(e.Density, 'f', 6, 64),
+					strconv.Itoa(e.Min.X),
+					strconv.Itoa(e.Min.Y),
+					strconv.Itoa(e.Max.X),
+					strconv.Itoa(e.Max.Y),
+				})
+				if err != nil {
+					failed(err)
+				}
+			case gol.FinalTurnComplete:
+				//flush before passing it on, main may exit as soon as it sees this event
+				if writer == nil {
+					break
+				}
+				writer.Flush()
+				if err := writer.Error(); err != nil {
+					failed(err)
+				}
+			}
+			out <- event
+		}
+	}()
+	return out
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestStats checks the TurnStats events for 100 turns of the 16x16 image against check/alive,
+// and that births, deaths and CellFlipped events agree with the change in alive cells.
+func TestStats(t *testing.T) {
+	p := gol.Params{Turns: 100, Threads: 3, ImageWidth: 16, ImageHeight: 16, Stats: true}
+	alive := readAliveCounts(p.ImageWidth, p.ImageHeight)
+
+	dir, err := ioutil.TempDir("", "gol-stats")
+	util.Check(err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "stats.csv")
+
+	golEvents := make(chan gol.Event)
+	result := make(chan error, 1)
+	go func() {
+		result <- gol.Run(p, golEvents, nil)
+	}()
+
+	previous := 0 //alive cells after the previous turn, starting with the CellFlipped events for the image
+	flipped := 0
+	turns := 0
+	for event := range writeStats(filename, golEvents) {
+		switch e := event.(type) {
+		case gol.CellFlipped:
+			if e.CompletedTurns == 0 {
+				previous++
+			} else {
+				flipped++
+			}
+		case gol.TurnStats:
+			turns++
+			if e.Alive != alive[e.CompletedTurns] {
+				t.Errorf("At turn %v expected %v alive cells, got %v instead", e.CompletedTurns, alive[e.CompletedTurns], e.Alive)
+			}
+			if e.Alive-previous != e.Births-e.Deaths || e.Births+e.Deaths != flipped {
+				t.Errorf("At turn %v got %v births and %v deaths from %v flips, but alive cells went from %v to %v",
+					e.CompletedTurns, e.Births, e.Deaths, flipped, previous, e.Alive)
+			}
+			if len(e.Bands) != p.Threads {
+				t.Errorf("expected %v bands, got %v", p.Threads, len(e.Bands))
+			}
+			previous = e.Alive
+			flipped = 0
+		}
+	}
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+	if turns != p.Turns {
+		t.Fatalf("expected %v TurnStats events, got %v", p.Turns, turns)
+	}
+
+	file, err := os.Open(filename)
+	util.Check(err)
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	util.Check(err)
+	if len(rows) != p.Turns+1 {
+		t.Fatalf("expected %v rows in %v, got %v", p.Turns+1, filename, len(rows))
+	}
+	for _, row := range rows[1:] {
+		turn, _ := strconv.Atoi(row[0])
+		count, _ := strconv.Atoi(row[1])
+		if count != alive[turn] {
+			t.Errorf("row for turn %v has %v alive cells, expected %v", turn, count, alive[turn])
+		}
+	}
+}
+
+// TestStatsError checks that events still go through writeStats when the file can't be created.
+func TestStatsError(t *testing.T) {
+	events := make(chan gol.Event, 3)
+	events <- gol.TurnStats{CompletedTurns: 1}
+	events <- gol.TurnComplete{CompletedTurns: 1}
+	events <- gol.FinalTurnComplete{CompletedTurns: 1}
+	close(events)
+	passed := 0
+	for range writeStats(filepath.Join("no-such-directory", "stats.csv"), events) {
+		passed++
+	}
+	if passed != 3 {
+		t.Errorf("expected 3 events passed on, got %v", passed)
+	}
+}
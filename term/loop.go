@@ -0,0 +1,188 @@
+package term
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/util"
+	"uk.ac.bris.cs/gameoflife/visual"
+)
+
+// ANSI escape sequences used to draw over the same part of the screen every frame.
+const (
+	clearScreen = "\x1b[2J"
+	cursorHome  = "\x1b[H"
+	clearLine   = "\x1b[K"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+)
+
+// Options are the terminal viewer settings that can be chosen on the command line.
+type Options struct {
+	Braille bool    // draw 2x4 cells per character instead of 1x2, for bigger boards
+	FPS     float64 // most frames to draw per second, skipping turns in between. 0 draws every turn
+}
+
+// Run draws the board in the terminal until the events channel is closed or the final turn completes.
+// Keys typed in the terminal are sent to keyPresses, just like with sdl.Run. Ctrl-C quits like 'q',
+// and pressing it again exits straight away, putting the terminal back first either way.
+// Only the top left of a board too big for the terminal is drawn.
+func Run(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, opts Options) {
+	world := make([][]uint8, p.ImageHeight)
+	for y := range world {
+		world[y] = make([]uint8, p.ImageWidth)
+	}
+	draw := util.HalfBlockRows
+	cellsPerColumn, cellsPerRow := 1, 2 //cells drawn in each character
+	if opts.Braille {
+		draw = util.BrailleRows
+		cellsPerColumn, cellsPerRow = 2, 4
+	}
+
+	keys, restore := readKeys()
+	out := bufio.NewWriter(os.Stdout)
+	fmt.Fprint(out, clearScreen, hideCursor)
+	cleanUp := func() {
+		fmt.Fprint(out, showCursor)
+		out.Flush()
+		restore()
+	}
+	defer cleanUp()
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+	quitting := false //Ctrl-C has already asked the game to quit
+
+	frames := visual.NewFrameLimiter(opts.FPS)
+	turn, alive := 0, 0
+	state := gol.Executing
+	message := "" //the last event worth mentioning, shown under the board
+	render := func() {
+		//crop the board to the terminal, leaving a line for the status above it and the message below
+		width, height := p.ImageWidth, p.ImageHeight
+		cropped := ""
+		if columns, rows, ok := terminalSize(int(os.Stdout.Fd())); ok {
+			if max := columns * cellsPerColumn; width > max {
+				width = max
+			}
+			if max := (rows - 2) * cellsPerRow; height > max {
+				height = max
+			}
+			if width < p.ImageWidth || height < p.ImageHeight {
+				cropped = fmt.Sprintf(" (showing %vx%v)", width, height)
+			}
+		}
+		fmt.Fprint(out, cursorHome)
+		fmt.Fprintf(out, "Turn %-8v Alive %-8v %v%v%v\n", turn, alive, state, cropped, clearLine)
+		if height > 0 {
+			for _, row := range draw(world, width, height) {
+				fmt.Fprint(out, row, clearLine, "\n")
+			}
+		}
+		fmt.Fprint(out, message, clearLine, "\n")
+		out.Flush()
+		frames.Drawn()
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-interrupts:
+			if quitting {
+				cleanUp()
+				os.Exit(130)
+			}
+			quitting = true
+			message = "Quitting, press Ctrl-C again to exit straight away"
+			render()
+			keyPresses <- 'q'
+		case key := <-keys:
+			switch key {
+			case 'p', 's', 'q', 'k', 'c', 'a', 'n', 'b', 'f', '+', '-':
+				keyPresses <- key
+			case '=':
+				keyPresses <- '+'
+			}
+		case event, ok := <-events:
+			if !ok {
+				render()
+				return
+			}
+			switch e := event.(type) {
+			case gol.CellFlipped:
+				world[e.Cell.Y][e.Cell.X] = ^world[e.Cell.Y][e.Cell.X]
+				if world[e.Cell.Y][e.Cell.X] == 0xFF {
+					alive++
+				} else {
+					alive--
+				}
+				//no TurnComplete will come while paused, so show edits straight away
+				if state == gol.Paused {
+					render()
+				}
+			case gol.TurnComplete:
+				turn = e.CompletedTurns
+				if frames.TurnComplete() {
+					render()
+				}
+			case gol.FinalTurnComplete:
+				turn = e.CompletedTurns
+				render()
+				return
+			case gol.StateChange:
+				state = e.NewState
+				turn = e.CompletedTurns
+				render()
+			default:
+				if len(event.String()) > 0 {
+					message = strings.TrimSpace(fmt.Sprintf("Completed Turns %-8v%v", event.GetCompletedTurns(), event))
+					render()
+				}
+			}
+		case <-ticker.C:
+			if frames.CatchUp() {
+				render()
+			}
+		}
+	}
+}
+
+// readKeys puts the terminal into raw mode and sends every key typed to the returned channel.
+// restore puts the terminal back to normal. If raw mode isn't available keys still arrive,
+// but only once Enter is pressed.
+func readKeys() (keys <-chan rune, restore func()) {
+	typed := make(chan rune, 10)
+	restore, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		restore = func() {}
+	}
+	go func() {
+		in := bufio.NewReader(os.Stdin)
+		for {
+			key, _, err := in.ReadRune()
+			if err != nil {
+				return
+			}
+			typed <- key
+		}
+	}()
+	return typed, restore
+}
+
+// Viewer shows the game in the terminal. It is a visual.Visualiser.
+// Cells can't be edited from the terminal, so edits are never sent.
+type Viewer struct {
+	Options Options
+}
+
+// Visualise runs the terminal viewer with v's options until the final turn.
+func (v Viewer) Visualise(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+	Run(p, events, keyPresses, v.Options)
+}
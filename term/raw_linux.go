@@ -0,0 +1,42 @@
+package term
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// makeRaw turns off line buffering and echo on the terminal, so single key presses can be read
+// straight away. Output processing and Ctrl-C are left alone, Run catches the signal to put the
+// terminal back. The returned function puts the terminal back how it was.
+func makeRaw(fd int) (func(), error) {
+	var old syscall.Termios
+	if err := ioctl(fd, syscall.TCGETS, &old); err != nil {
+		return nil, err
+	}
+	raw := old
+	raw.Lflag &^= syscall.ICANON | syscall.ECHO
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, syscall.TCSETS, &raw); err != nil {
+		return nil, err
+	}
+	return func() { ioctl(fd, syscall.TCSETS, &old) }, nil
+}
+
+// terminalSize returns the number of columns and rows of the terminal, ok is false if fd isn't one.
+func terminalSize(fd int) (columns, rows int, ok bool) {
+	var size struct{ rows, columns, xPixels, yPixels uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&size)))
+	if errno != 0 || size.columns == 0 || size.rows == 0 {
+		return 0, 0, false
+	}
+	return int(size.columns), int(size.rows), true
+}
+
+func ioctl(fd int, request uintptr, t *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, uintptr(unsafe.Pointer(t)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
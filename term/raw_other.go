@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+package term
+
+import "errors"
+
+// makeRaw is only implemented for Linux. Elsewhere keys still work, but need Enter after them.
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw mode is not supported on this platform")
+}
+
+// terminalSize is only implemented for Linux, elsewhere the whole board is drawn.
+func terminalSize(fd int) (columns, rows int, ok bool) {
+	return 0, 0, false
+}
@@ -106,3 +106,52 @@ func squaresToStrings(given, expected [][]uint8, width, height int) []string {
 
 	return output
 }
+
+// halfBlocks are indexed by whether the top cell (bit 1) and bottom cell (bit 2) are alive.
+var halfBlocks = [4]rune{' ', '▀', '▄', '█'}
+
+// brailleDots gives the dot of a braille character for each cell in its 2 wide, 4 tall block.
+var brailleDots = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+// HalfBlockRows draws the world as lines of text holding two rows of cells each,
+// using the upper and lower half block characters so cells come out roughly square.
+func HalfBlockRows(given [][]uint8, width, height int) []string {
+	var output []string
+	for i := 0; i < height; i += 2 {
+		var row strings.Builder
+		for j := 0; j < width; j++ {
+			block := 0
+			if given[i][j] == 0xFF {
+				block |= 1
+			}
+			if i+1 < height && given[i+1][j] == 0xFF {
+				block |= 2
+			}
+			row.WriteRune(halfBlocks[block])
+		}
+		output = append(output, row.String())
+	}
+	return output
+}
+
+// BrailleRows draws the world as lines of braille characters, each one holding 2x4 cells,
+// for boards too big to fit on the screen with HalfBlockRows.
+func BrailleRows(given [][]uint8, width, height int) []string {
+	var output []string
+	for i := 0; i < height; i += 4 {
+		var row strings.Builder
+		for j := 0; j < width; j += 2 {
+			char := rune(0x2800)
+			for dy := 0; dy < 4 && i+dy < height; dy++ {
+				for dx := 0; dx < 2 && j+dx < width; dx++ {
+					if given[i+dy][j+dx] == 0xFF {
+						char |= brailleDots[dy][dx]
+					}
+				}
+			}
+			row.WriteRune(char)
+		}
+		output = append(output, row.String())
+	}
+	return output
+}
@@ -0,0 +1,21 @@
+//go:build !cgo || nosdl
+// +build !cgo nosdl
+
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"uk.ac.bris.cs/gameoflife/term"
+	"uk.ac.bris.cs/gameoflife/visual"
+)
+
+// addSDLFlags does nothing, as this binary was built without the SDL window (-tags nosdl or CGO_ENABLED=0).
+func addSDLFlags(flags *flag.FlagSet) {}
+
+// sdlViewer falls back to the terminal viewer, as this binary was built without the SDL window.
+func sdlViewer(fps float64) visual.Visualiser {
+	fmt.Println("Built without SDL, using the terminal viewer. Use -noVis for no viewer at all.")
+	return term.Viewer{Options: term.Options{FPS: fps}}
+}
@@ -0,0 +1,35 @@
+//go:build cgo && !nosdl
+// +build cgo,!nosdl
+
+package main
+
+import (
+	"flag"
+
+	"uk.ac.bris.cs/gameoflife/sdl"
+	"uk.ac.bris.cs/gameoflife/visual"
+)
+
+// sdlOptions are filled in by the flags added in addSDLFlags.
+var sdlOptions sdl.Options
+
+// addSDLFlags adds the flags that only mean something to the SDL window.
+func addSDLFlags(flags *flag.FlagSet) {
+	flags.StringVar(
+		&sdlOptions.Palette,
+		"palette",
+		"classic",
+		"Specify the colour scheme of the SDL window: "+sdl.PaletteNames()+". Press v to cycle through them.")
+
+	flags.BoolVar(
+		&sdlOptions.AgeMode,
+		"age",
+		false,
+		"Colour cells by how long they have been alive, or how recently they died. Press g to toggle.")
+}
+
+// sdlViewer shows the board in an SDL window.
+func sdlViewer(fps float64) visual.Visualiser {
+	sdlOptions.FPS = fps
+	return sdl.Viewer{Options: sdlOptions}
+}
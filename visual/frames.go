@@ -0,0 +1,41 @@
+package visual
+
+import "time"
+
+// FrameLimiter decides when a viewer draws the board. When turns come faster than frames
+// only some of them are drawn, and the last one is caught up on once there's nothing else to do.
+type FrameLimiter struct {
+	interval time.Duration
+	last     time.Time //when the last frame was drawn
+	skipped  bool      //a turn completed without being drawn
+}
+
+// NewFrameLimiter draws at most fps frames per second. 0 draws every turn.
+func NewFrameLimiter(fps float64) *FrameLimiter {
+	f := &FrameLimiter{}
+	if fps > 0 {
+		f.interval = time.Duration(float64(time.Second) / fps)
+	}
+	return f
+}
+
+// Drawn records that a frame has just been drawn.
+func (f *FrameLimiter) Drawn() {
+	f.last = time.Now()
+	f.skipped = false
+}
+
+// TurnComplete reports whether a turn that has just completed should be drawn now.
+// If not, it is remembered so CatchUp can draw it later.
+func (f *FrameLimiter) TurnComplete() bool {
+	if time.Since(f.last) >= f.interval {
+		return true
+	}
+	f.skipped = true
+	return false
+}
+
+// CatchUp reports whether a turn that wasn't drawn can be drawn now. Viewers call it when they have nothing else to do.
+func (f *FrameLimiter) CatchUp() bool {
+	return f.skipped && time.Since(f.last) >= f.interval
+}
@@ -0,0 +1,96 @@
+package visual
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+)
+
+// Visualiser consumes the events of a game, e.g. by drawing the board or writing it to a file.
+// Visualise returns once the final turn has completed or the events channel is closed.
+// Key presses and cell edits can be sent back to the distributor; either channel may be nil
+// if the visualiser doesn't use it.
+type Visualiser interface {
+	Visualise(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit)
+}
+
+// Func lets an ordinary function be used as a Visualiser.
+type Func func(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit)
+
+// Visualise calls f.
+func (f Func) Visualise(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+	f(p, events, keyPresses, edits)
+}
+
+// Headless shows nothing, it just waits for the final turn.
+type Headless struct{}
+
+// Visualise waits for the final turn.
+func (Headless) Visualise(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+	for event := range events {
+		if _, ok := event.(gol.FinalTurnComplete); ok {
+			return
+		}
+	}
+}
+
+// Log writes every event that has something to say, in the same format the SDL window prints them.
+type Log struct {
+	Out io.Writer
+}
+
+// Visualise writes events to l.Out until the final turn.
+func (l Log) Visualise(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+	for event := range events {
+		if len(event.String()) > 0 {
+			fmt.Fprintf(l.Out, "Completed Turns %-8v%v\n", event.GetCompletedTurns(), event)
+		}
+		if _, ok := event.(gol.FinalTurnComplete); ok {
+			return
+		}
+	}
+}
+
+// FanOut gives every event to each of its visualisers, so they can all watch the same game.
+// The first one runs on the calling goroutine, so an SDL window (which has to stay on the
+// main thread) should go first. The others each get their own goroutine.
+type FanOut []Visualiser
+
+// Visualise returns once the events channel is closed and every visualiser has returned.
+func (f FanOut) Visualise(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+	if len(f) == 0 {
+		Headless{}.Visualise(p, events, keyPresses, edits)
+		return
+	}
+	outs := make([]chan gol.Event, len(f))
+	for i := range outs {
+		outs[i] = make(chan gol.Event, cap(events))
+	}
+
+	var wg sync.WaitGroup
+	run := func(v Visualiser, out chan gol.Event) {
+		v.Visualise(p, out, keyPresses, edits)
+		//keep taking events after returning, so the others aren't held up
+		for range out {
+		}
+		wg.Done()
+	}
+	wg.Add(len(f))
+	for i := 1; i < len(f); i++ {
+		go run(f[i], outs[i])
+	}
+	go func() {
+		for event := range events {
+			for _, out := range outs {
+				out <- event
+			}
+		}
+		for _, out := range outs {
+			close(out)
+		}
+	}()
+	run(f[0], outs[0])
+	wg.Wait()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/gol"
+	"uk.ac.bris.cs/gameoflife/visual"
+)
+
+// TestFanOut checks that every visualiser in a FanOut sees the whole game, including one that
+// stops early, and that the log visualiser reports the saved image.
+func TestFanOut(t *testing.T) {
+	p := gol.Params{Turns: 10, Threads: 2, ImageWidth: 16, ImageHeight: 16, ReportInterval: -1}
+	events := make(chan gol.Event)
+	result := make(chan error, 1)
+	go func() {
+		result <- gol.Run(p, events, nil)
+	}()
+
+	var first, second []gol.Event
+	collect := func(into *[]gol.Event) visual.Visualiser {
+		return visual.Func(func(p gol.Params, events <-chan gol.Event, keyPresses chan<- rune, edits chan<- gol.CellEdit) {
+			for event := range events {
+				*into = append(*into, event)
+			}
+		})
+	}
+	var log bytes.Buffer
+	visual.FanOut{collect(&first), visual.Headless{}, visual.Log{Out: &log}, collect(&second)}.Visualise(p, events, nil, nil)
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+
+	if len(first) == 0 || len(first) != len(second) {
+		t.Fatalf("expected both visualisers to see the same events, got %v and %v", len(first), len(second))
+	}
+	for i := range first {
+		if !reflect.DeepEqual(first[i], second[i]) {
+			t.Fatalf("event %v differs: %v and %v", i, first[i], second[i])
+		}
+	}
+	if _, ok := first[len(first)-1].(gol.StateChange); !ok {
+		t.Errorf("expected the last event to be the StateChange to Quitting, got %#v", first[len(first)-1])
+	}
+	if !strings.Contains(log.String(), "File 16x16x10 output complete") {
+		t.Errorf("expected the log to mention the saved image, got:\n%v", log.String())
+	}
+}
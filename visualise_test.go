@@ -0,0 +1,25 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"uk.ac.bris.cs/gameoflife/util"
+)
+
+// TestTerminalRows checks the half block and braille drawings used by the terminal viewer on a glider.
+func TestTerminalRows(t *testing.T) {
+	glider := [][]uint8{
+		{0x00, 0xFF, 0x00},
+		{0x00, 0x00, 0xFF},
+		{0xFF, 0xFF, 0xFF},
+	}
+	halfBlocks := []string{" ▀▄", "▀▀▀"}
+	if rows := util.HalfBlockRows(glider, 3, 3); !reflect.DeepEqual(rows, halfBlocks) {
+		t.Errorf("expected half blocks %q, got %q", halfBlocks, rows)
+	}
+	braille := []string{"⠬⠆"}
+	if rows := util.BrailleRows(glider, 3, 3); !reflect.DeepEqual(rows, braille) {
+		t.Errorf("expected braille %q, got %q", braille, rows)
+	}
+}